@@ -0,0 +1,106 @@
+package csv
+
+import (
+	"errors"
+	"io"
+)
+
+// BadRecord describes a record that Scan skipped because it couldn't be read. See OnBadRecord.
+type BadRecord struct {
+	// Line is the line where the record starts, counting from 1, and Offset is its byte offset in the input.
+	Line   int
+	Offset int64
+	// Raw is the input from the start of the record to its end, including the line ending. With
+	// StdlibCompatible it ends at the end of the line where the error was found instead. If the record is
+	// larger than allowed by MaxCellSize, MaxRowSize or MaxCellsPerRow, Raw stops where the limit was found.
+	// Raw is only valid during the call to the OnBadRecord function.
+	Raw []byte
+	// Err is the *ParseError that describes the problem.
+	Err error
+}
+
+// OnBadRecord makes the Reader skip records it can't read rather than failing. When Scan, Read or Bytes
+// finds an error in a record it calls f with the details, skips to the start of the next record and carries
+// on from there. Errors in the input are skipped in this way, as are records with the wrong number of cells if
+// FieldsPerRecord is used and records that exceed the limits of MaxCellSize, MaxRowSize or MaxCellsPerRow.
+// Errors reading the input are returned as usual, as are errors in the header.
+//
+// The end of a bad record is found using the rules for LazyQuotes, so a quoted cell continues past the end of
+// a line until its closing quote, and a quote that isn't followed by a delimiter, a line ending or another
+// quote is taken as part of the cell. With StdlibCompatible the Reader instead carries on from the line after
+// the error, as encoding/csv does.
+//
+// If f returns an error Scan returns it. The Reader is then at the start of the next record, so you may carry
+// on reading if you wish. Skipped records are counted, and the count is available from Skipped. OnBadRecord
+// can't be used with ParallelReader.
+func OnBadRecord(f func(bad BadRecord) error) ReaderOption {
+	return readerOptionFunc(func(r *Reader) {
+		r.onBadRecord = f
+		r.keepRaw = true
+	})
+}
+
+// Quarantine is OnBadRecord with a function that writes the raw input of each bad record to w, so it can be
+// examined or fixed later. A line ending is added to a record at the end of the input that doesn't have one.
+func Quarantine(w io.Writer) ReaderOption {
+	return OnBadRecord(func(bad BadRecord) error {
+		if _, err := w.Write(bad.Raw); err != nil {
+			return err
+		}
+		if n := len(bad.Raw); n > 0 && bad.Raw[n-1] != '\n' {
+			_, err := w.Write([]byte{'\n'})
+			return err
+		}
+		return nil
+	})
+}
+
+// Skipped returns the number of bad records that have been skipped since the Reader was created or last
+// given new input with SetInput. Records are only skipped if OnBadRecord or Quarantine is used.
+func (r *Reader) Skipped() int {
+	return r.skipped
+}
+
+// skipBadRecord skips the rest of a record that scanRow returned err for, and reports it to the OnBadRecord
+// function. The end of the record is found with the rules used for LazyQuotes, unless we're compatible with
+// encoding/csv. err is returned as is if it isn't an error in the input.
+func (r *Reader) skipBadRecord(err error) error {
+	var perr *ParseError
+	if !errors.As(err, &perr) {
+		return err
+	}
+
+	truncated := false
+	if !r.rowDone {
+		if errors.Is(err, ErrCellTooLarge) || errors.Is(err, ErrRowTooLarge) || errors.Is(err, ErrTooManyCells) {
+			// We don't keep any more of a record that's too large. Marking the row done stops fill keeping it
+			r.rawScratch = append(r.rawScratch[:0], r.buf[r.recordOffset-r.bufOffset:r.pos]...)
+			truncated = true
+			r.rowDone = true
+		}
+		skip := r.skipRecord
+		if r.compat {
+			// encoding/csv carries on from the line after the error
+			skip = r.skipLine
+		}
+		if err := skip(); err != nil {
+			if err != io.EOF {
+				return err
+			}
+			r.fileDone = true
+		}
+		r.rowDone = true
+	}
+	raw := r.rawScratch
+	if !truncated {
+		raw = r.buf[r.recordOffset-r.bufOffset : r.pos]
+	}
+
+	r.skipped++
+	return r.onBadRecord(BadRecord{
+		Line:   r.recordLine,
+		Offset: r.recordOffset,
+		Raw:    raw,
+		Err:    err,
+	})
+}
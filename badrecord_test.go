@@ -0,0 +1,289 @@
+package csv_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/philpearl/csv"
+	"github.com/stretchr/testify/assert"
+)
+
+type badRecord struct {
+	Line   int
+	Offset int64
+	Raw    string
+	Err    string
+}
+
+// readSkipping reads in, returning the rows and the bad records that were skipped
+func readSkipping(in io.Reader, opts ...csv.ReaderOption) (rows [][]string, bad []badRecord, err error) {
+	opts = append(opts, csv.OnBadRecord(func(b csv.BadRecord) error {
+		bad = append(bad, badRecord{Line: b.Line, Offset: b.Offset, Raw: string(b.Raw), Err: b.Err.Error()})
+		return nil
+	}))
+	r := csv.NewReader(in, opts...)
+	for row, err := range r.Records() {
+		if err != nil {
+			return rows, bad, err
+		}
+		rows = append(rows, slices.Clone(row))
+	}
+	if r.Skipped() != len(bad) {
+		return rows, bad, fmt.Errorf("skipped %d, but %d bad records", r.Skipped(), len(bad))
+	}
+	return rows, bad, nil
+}
+
+func TestOnBadRecord(t *testing.T) {
+	long := strings.Repeat("x", 10000)
+
+	tests := []struct {
+		name string
+		in   string
+		opts []csv.ReaderOption
+		exp  [][]string
+		bad  []badRecord
+	}{
+		{
+			name: "none",
+			in:   "a,b\nc,d\n",
+			exp:  [][]string{{"a", "b"}, {"c", "d"}, {""}},
+		},
+		{
+			name: "after quote",
+			in:   "a,b\n\"c\"d\",e\nf,g",
+			exp:  [][]string{{"a", "b"}, {"f", "g"}},
+			bad: []badRecord{
+				{Line: 2, Offset: 4, Raw: "\"c\"d\",e\n", Err: "record 2, cell 0 (line 2, offset 7): unexpected char d after terminating quote"},
+			},
+		},
+		{
+			name: "quoted line ending",
+			in:   "a,b\n\"x\"y,\"multi\nline\"\nc,d\n",
+			exp:  [][]string{{"a", "b"}, {"c", "d"}, {""}},
+			bad: []badRecord{
+				{Line: 2, Offset: 4, Raw: "\"x\"y,\"multi\nline\"\n", Err: "record 2, cell 0 (line 2, offset 7): unexpected char y after terminating quote"},
+			},
+		},
+		{
+			name: "strict",
+			in:   "a,b\r\nc\"d,e\r\n\"f\"g\"\r\nh,\"i\nj\"\r\nk,l\r\n",
+			opts: []csv.ReaderOption{csv.Strict(), csv.SkipBlankLines()},
+			exp:  [][]string{{"a", "b"}, {"h", "i\nj"}, {"k", "l"}},
+			bad: []badRecord{
+				{Line: 2, Offset: 5, Raw: "c\"d,e\r\n", Err: "record 2, cell 0 (line 2, offset 6): bare quote in non-quoted cell"},
+				{Line: 3, Offset: 12, Raw: "\"f\"g\"\r\n", Err: "record 3, cell 0 (line 3, offset 15): extraneous or missing quote in quoted cell"},
+			},
+		},
+		{
+			name: "consecutive",
+			in:   "\"a\"b\"\n\"c\"d\"\n",
+			opts: []csv.ReaderOption{csv.SkipBlankLines()},
+			bad: []badRecord{
+				{Line: 1, Offset: 0, Raw: "\"a\"b\"\n", Err: "record 1, cell 0 (line 1, offset 3): unexpected char b after terminating quote"},
+				{Line: 2, Offset: 6, Raw: "\"c\"d\"\n", Err: "record 2, cell 0 (line 2, offset 9): unexpected char d after terminating quote"},
+			},
+		},
+		{
+			name: "at end",
+			in:   "a,b\nc,\"d\"e",
+			exp:  [][]string{{"a", "b"}},
+			bad: []badRecord{
+				{Line: 2, Offset: 4, Raw: "c,\"d\"e", Err: "record 2, cell 1 (line 2, offset 9): unexpected char e after terminating quote"},
+			},
+		},
+		{
+			name: "unterminated quote",
+			in:   "a,b\nc,\"d\ne,f\n",
+			exp:  [][]string{{"a", "b"}},
+			bad: []badRecord{
+				{Line: 2, Offset: 4, Raw: "c,\"d\ne,f\n", Err: "record 2, cell 1 (line 4, offset 13): unexpected EOF"},
+			},
+		},
+		{
+			name: "fields",
+			in:   "a,b\nc\nd,e\n",
+			opts: []csv.ReaderOption{csv.FieldsPerRecord(0), csv.SkipBlankLines()},
+			exp:  [][]string{{"a", "b"}, {"d", "e"}},
+			bad: []badRecord{
+				{Line: 2, Offset: 4, Raw: "c\n", Err: "record 2, cell 1 (line 2, offset 4): wrong number of fields: have 1, want 2"},
+			},
+		},
+		{
+			name: "header",
+			in:   "name,n\nhat,\"1\"x\"\ncoat,2\n",
+			opts: []csv.ReaderOption{csv.UseHeader(), csv.SkipBlankLines()},
+			exp:  [][]string{{"coat", "2"}},
+			bad: []badRecord{
+				{Line: 2, Offset: 7, Raw: "hat,\"1\"x\"\n", Err: "record 2, cell 1 \"n\" (line 2, offset 14): unexpected char x after terminating quote"},
+			},
+		},
+		{
+			name: "long",
+			in:   "a,b\n\"" + long + "\"x\"," + long + "\nc,d\n",
+			opts: []csv.ReaderOption{csv.SkipBlankLines()},
+			exp:  [][]string{{"a", "b"}, {"c", "d"}},
+			bad: []badRecord{
+				{Line: 2, Offset: 4, Raw: "\"" + long + "\"x\"," + long + "\n", Err: "record 2, cell 0 (line 2, offset 10006): unexpected char x after terminating quote"},
+			},
+		},
+		{
+			name: "too many cells",
+			in:   "a,b\nc,d,e\nf,g\n",
+			opts: []csv.ReaderOption{csv.MaxCellsPerRow(2), csv.SkipBlankLines()},
+			exp:  [][]string{{"a", "b"}, {"f", "g"}},
+			bad: []badRecord{
+				{Line: 2, Offset: 4, Raw: "c,d,", Err: "record 2, cell 2 (line 2, offset 8): too many cells in row (limit 2)"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rows, bad, err := readSkipping(strings.NewReader(test.in), test.opts...)
+			assert.NoError(t, err)
+			assert.Equal(t, test.exp, rows)
+			assert.Equal(t, test.bad, bad)
+
+			// We should get the same results however the input is read
+			rows1, bad1, err := readSkipping(iotest.OneByteReader(strings.NewReader(test.in)), test.opts...)
+			assert.NoError(t, err)
+			assert.Equal(t, rows, rows1)
+			assert.Equal(t, bad, bad1)
+		})
+	}
+}
+
+func TestOnBadRecordTooLarge(t *testing.T) {
+	long := strings.Repeat("x", 100000)
+	in := "a,b\nc," + long + "\nd,e\n"
+	tests := []struct {
+		limit csv.ReaderOption
+		err   string
+	}{
+		{limit: csv.MaxRowSize(5000), err: "record 2, cell 1 (line 2, offset 4): row too large (limit 5000)"},
+		{limit: csv.MaxCellSize(5000), err: "record 2, cell 1 (line 2, offset 6): cell too large (limit 5000)"},
+	}
+	for _, test := range tests {
+		t.Run(test.err, func(t *testing.T) {
+			rows, bad, err := readSkipping(iotest.OneByteReader(strings.NewReader(in)), test.limit, csv.SkipBlankLines())
+			assert.NoError(t, err)
+			assert.Equal(t, [][]string{{"a", "b"}, {"d", "e"}}, rows)
+			if assert.Len(t, bad, 1) {
+				// Only the start of the record is kept
+				assert.True(t, strings.HasPrefix(bad[0].Raw, "c,xxx"))
+				assert.True(t, len(bad[0].Raw) > 5000 && len(bad[0].Raw) < 6000, "raw is %d bytes", len(bad[0].Raw))
+				assert.Equal(t, test.err, bad[0].Err)
+			}
+		})
+	}
+}
+
+// sizeReader records the largest read, which shows how large the Reader's buffer has grown
+type sizeReader struct {
+	io.Reader
+	max int
+}
+
+func (s *sizeReader) Read(p []byte) (int, error) {
+	s.max = max(s.max, len(p))
+	return s.Reader.Read(p)
+}
+
+func TestOnBadRecordSkippedLines(t *testing.T) {
+	// Comment lines before a record aren't part of it, so they aren't kept
+	in := strings.Repeat("# comment\n", 10000) + "a,b\n\"c\"d\n"
+	r := csv.NewReader(nil, csv.Comment('#'), csv.SkipBlankLines(), csv.Quarantine(io.Discard))
+	for range 2 {
+		// The second time round the record offset from the first input mustn't be used
+		s := &sizeReader{Reader: strings.NewReader(in)}
+		r.SetInput(s)
+		assert.NoError(t, r.Scan())
+		assert.Equal(t, "a", r.Text(0))
+		assert.Equal(t, io.EOF, r.Scan())
+		assert.Equal(t, 1, r.Skipped())
+		assert.True(t, s.max <= 4096, "buffer grew to %d", s.max)
+	}
+}
+
+func TestOnBadRecordError(t *testing.T) {
+	in := "a\n\"b\"c\"\nd\n"
+	tooMany := errors.New("too many bad records")
+	var bad []string
+	r := csv.NewReader(strings.NewReader(in), csv.OnBadRecord(func(b csv.BadRecord) error {
+		bad = append(bad, string(b.Raw))
+		return tooMany
+	}))
+
+	assert.NoError(t, r.Scan())
+	assert.Equal(t, "a", r.Text(0))
+	assert.Equal(t, tooMany, r.Scan())
+	assert.Equal(t, []string{"\"b\"c\"\n"}, bad)
+	assert.Equal(t, 1, r.Skipped())
+
+	// We can carry on reading after the error
+	assert.NoError(t, r.Scan())
+	assert.Equal(t, "d", r.Text(0))
+}
+
+func TestOnBadRecordHeaderError(t *testing.T) {
+	r := csv.NewReader(strings.NewReader("\"a\"b\nc\n"), csv.UseHeader(), csv.Quarantine(io.Discard))
+	err := r.Scan()
+	assert.EqualError(t, err, "record 1, cell 0 (line 1, offset 3): unexpected char b after terminating quote")
+	assert.Equal(t, 0, r.Skipped())
+}
+
+func TestQuarantine(t *testing.T) {
+	in := "a,b\n\"c\"d\",e\nf,g\nh,\"i\"j"
+	var quarantine bytes.Buffer
+	r := csv.NewReader(strings.NewReader(in), csv.Quarantine(&quarantine))
+	var rows [][]string
+	for row, err := range r.Records() {
+		if !assert.NoError(t, err) {
+			return
+		}
+		rows = append(rows, slices.Clone(row))
+	}
+	assert.Equal(t, [][]string{{"a", "b"}, {"f", "g"}}, rows)
+	assert.Equal(t, "\"c\"d\",e\nh,\"i\"j\n", quarantine.String())
+	assert.Equal(t, 2, r.Skipped())
+
+	r.SetInput(strings.NewReader("a\n"))
+	assert.Equal(t, 0, r.Skipped())
+}
+
+func TestQuarantineWriteError(t *testing.T) {
+	r := csv.NewReader(strings.NewReader("\"a\"b\n"), csv.Quarantine(failingWriter{}))
+	assert.EqualError(t, r.Scan(), "disk full")
+}
+
+func TestParallelOnBadRecord(t *testing.T) {
+	assert.Panics(t, func() {
+		csv.NewParallelReader(strings.NewReader(""), 0, csv.Quarantine(io.Discard))
+	})
+}
+
+func ExampleQuarantine() {
+	in := strings.NewReader("name,age\nAlice,34\n\"Bob\" \"by\",27\nCarol,51\n")
+	r := csv.NewReader(in, csv.UseHeader(), csv.SkipBlankLines(), csv.Quarantine(os.Stdout))
+	for row, err := range r.Rows() {
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Println("read", row.Text(0))
+	}
+	fmt.Println("skipped", r.Skipped())
+
+	// Output: read Alice
+	// "Bob" "by",27
+	// read Carol
+	// skipped 1
+}
@@ -4,9 +4,9 @@ package csv
 
 import "fmt"
 
-const _cellState_name = "cellStateBegincellStateInQuotecellStateInQuoteQuotecellStateInCellcellStateTrailingWhiteSpace"
+const _cellState_name = "cellStateBegincellStateInQuotecellStateInQuoteQuotecellStateInCellcellStateTrailingWhiteSpacecellStateSlashRcellStateInQuoteSlashRcellStateQuoteSlashR"
 
-var _cellState_index = [...]uint8{0, 14, 30, 51, 66, 93}
+var _cellState_index = [...]uint8{0, 14, 30, 51, 66, 93, 108, 130, 150}
 
 func (i cellState) String() string {
 	if i >= cellState(len(_cellState_index)-1) {
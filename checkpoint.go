@@ -0,0 +1,86 @@
+package csv
+
+import "io"
+
+// Checkpoint is a position between records in the input, from which a Reader can resume reading with Resume
+// or NewResumingReader. Its fields are exported so it can be saved, for instance as JSON.
+type Checkpoint struct {
+	// Offset is the byte offset in the input. The next record is the first after it, but comment or blank
+	// lines may come before it.
+	Offset int64
+	// Record is the number of records before Offset, including the header if there is one.
+	Record int
+	// Line is the line number at Offset, counting from 1.
+	Line int
+	// EOF is set if the Reader had reached the end of the input, so there are no more records.
+	EOF bool
+}
+
+// Checkpoint returns the position after the current record. If reading is resumed from it the next record
+// read is the one after the current record. The Checkpoint is only at the end of a record if the last call to
+// Scan, Read or Bytes succeeded, or returned an error wrapping ErrFieldCount.
+func (r *Reader) Checkpoint() Checkpoint {
+	return Checkpoint{
+		Offset: r.InputOffset(),
+		Record: r.record,
+		Line:   r.line,
+		EOF:    r.fileDone,
+	}
+}
+
+// NewResumingReader creates a Reader that resumes reading in from a Checkpoint returned by an earlier Reader
+// with the same options on the same input. See Resume.
+func NewResumingReader(in io.ReadSeeker, cp Checkpoint, opts ...ReaderOption) (*Reader, error) {
+	r := NewReader(nil, opts...)
+	if err := r.Resume(in, cp); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Resume is SetInput for resuming reading from a Checkpoint. The Checkpoint must have been returned by a
+// Reader with the same options reading the same input. Rows before the Checkpoint are not read again, except
+// that if UseHeader is used the header is read from the start of the input, and if FieldsPerRecord(0) is used
+// without a header the first record is read to find how many cells each record should have. Positions in
+// errors are the same as they would have been if the input had been read from the start. InputEncoding can't
+// be used, and Resume panics if it is.
+func (r *Reader) Resume(in io.ReadSeeker, cp Checkpoint) error {
+	r.checkSeekable("Resume")
+
+	if r.useHeader || (r.checkFields && r.fieldsPerRecord == 0) {
+		if _, err := in.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		r.SetInput(in)
+		if r.useHeader {
+			if err := r.readHeader(); err != nil {
+				return err
+			}
+			// A Checkpoint from before the header was read resumes after it
+			if cp.Record < r.record {
+				cp = r.Checkpoint()
+			}
+		}
+		r.readFieldCount()
+	}
+
+	if _, err := in.Seek(cp.Offset, io.SeekStart); err != nil {
+		return err
+	}
+	r.setInputAt(in, cp)
+	return nil
+}
+
+// setInputAt is SetInput for input that starts at the position recorded in cp. The header and the number of
+// cells we expect in each record are kept.
+func (r *Reader) setInputAt(in io.Reader, cp Checkpoint) {
+	header, fieldCount := r.header, r.fieldCount
+	r.SetInput(in)
+	r.header = header
+	r.fieldCount = fieldCount
+	r.bufOffset = cp.Offset
+	r.line = cp.Line
+	r.lineOffset = cp.Offset
+	r.record = cp.Record
+	r.fileDone = cp.EOF
+}
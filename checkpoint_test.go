@@ -0,0 +1,212 @@
+package csv_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/philpearl/csv"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResume(t *testing.T) {
+	for _, config := range testConfigs {
+		for i, in := range testInputs {
+			expRows, expErr := readSequential(strings.NewReader(in), config.opts...)
+			if expErr != "" {
+				continue
+			}
+			t.Run(fmt.Sprintf("%s/%d", config.name, i), func(t *testing.T) {
+				// Take a checkpoint before any rows are read, then after each row
+				r := csv.NewReader(strings.NewReader(in), config.opts...)
+				checkpoints := []csv.Checkpoint{r.Checkpoint()}
+				for range r.Rows() {
+					checkpoints = append(checkpoints, r.Checkpoint())
+				}
+
+				resumed := csv.NewReader(nil, config.opts...)
+				for n, cp := range checkpoints {
+					assert.NoError(t, resumed.Resume(strings.NewReader(in), cp))
+					var rows []rowDetail
+					for row, err := range resumed.Rows() {
+						if !assert.NoError(t, err) {
+							return
+						}
+						rows = append(rows, readRowDetail(row))
+					}
+					if n == len(expRows) {
+						assert.Empty(t, rows, "checkpoint %d", n)
+						continue
+					}
+					assert.Equal(t, expRows[n:], rows, "checkpoint %d", n)
+				}
+			})
+		}
+	}
+}
+
+func TestResumeCases(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		opts []csv.ReaderOption
+		// read is the number of rows read before the checkpoint is taken
+		read int
+		exp  []string
+		err  string
+	}{
+		{
+			name: "start",
+			in:   "a\nb\n",
+			opts: []csv.ReaderOption{csv.SkipBlankLines()},
+			exp:  []string{"a", "b"},
+		},
+		{
+			name: "before header",
+			in:   "h\na\nb\n",
+			opts: []csv.ReaderOption{csv.UseHeader(), csv.SkipBlankLines()},
+			exp:  []string{"a", "b"},
+		},
+		{
+			name: "after header",
+			in:   "h\na\nb\n",
+			opts: []csv.ReaderOption{csv.UseHeader(), csv.SkipBlankLines()},
+			read: 1,
+			exp:  []string{"b"},
+		},
+		{
+			name: "skipped lines",
+			in:   "a\n#x\n\nb\n",
+			opts: []csv.ReaderOption{csv.Comment('#'), csv.SkipBlankLines()},
+			read: 1,
+			exp:  []string{"b"},
+		},
+		{
+			name: "quoted",
+			in:   "\"a\nb\"\n\"c\nd\"\n",
+			opts: []csv.ReaderOption{csv.SkipBlankLines()},
+			read: 1,
+			exp:  []string{"c\nd"},
+		},
+		{
+			name: "field count",
+			in:   "a,1\nb,2\nc\n",
+			opts: []csv.ReaderOption{csv.FieldsPerRecord(0), csv.SkipBlankLines()},
+			read: 2,
+			err:  "record 3, cell 1 (line 3, offset 8): wrong number of fields: have 1, want 2",
+		},
+		{
+			name: "end",
+			in:   "a\nb",
+			read: 2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := csv.NewReader(strings.NewReader(test.in), test.opts...)
+			for range test.read {
+				assert.NoError(t, r.Scan())
+			}
+			cp := r.Checkpoint()
+
+			r, err := csv.NewResumingReader(strings.NewReader(test.in), cp, test.opts...)
+			if !assert.NoError(t, err) {
+				return
+			}
+			var cells []string
+			for row, err := range r.Rows() {
+				if err != nil {
+					assert.EqualError(t, err, test.err)
+					return
+				}
+				cells = append(cells, row.Text(0))
+			}
+			assert.Equal(t, test.exp, cells)
+		})
+	}
+}
+
+func TestResumeInputEncoding(t *testing.T) {
+	r := csv.NewReader(nil, csv.InputEncoding(csv.ISO8859_1))
+	assert.Panics(t, func() { _ = r.Resume(strings.NewReader(""), csv.Checkpoint{}) })
+}
+
+func TestNewResumingReader(t *testing.T) {
+	in := "name,n\nhat,1\ncoat,2\n\"big\nboots\",3\nscarf,4\n"
+
+	// Read part of the input, as a job might before it is stopped
+	r := csv.NewReader(strings.NewReader(in), csv.UseHeader(), csv.SkipBlankLines())
+	for range 3 {
+		assert.NoError(t, r.Scan())
+	}
+	cp := r.Checkpoint()
+	assert.Equal(t, csv.Checkpoint{Offset: 34, Record: 4, Line: 6}, cp)
+
+	r, err := csv.NewResumingReader(strings.NewReader(in), cp, csv.UseHeader(), csv.SkipBlankLines())
+	assert.NoError(t, err)
+	assert.NoError(t, r.Scan())
+	name, err := r.TextByName("name")
+	assert.NoError(t, err)
+	assert.Equal(t, "scarf", name)
+	_, err = r.IntByName("name")
+	assert.EqualError(t, err, `record 5, cell 0 "name" (line 6, offset 34): strconv.Atoi: parsing "scarf": invalid syntax`)
+	assert.Equal(t, io.EOF, r.Scan())
+}
+
+type failingSeeker struct {
+	io.Reader
+}
+
+func (failingSeeker) Seek(offset int64, whence int) (int64, error) {
+	return 0, errors.New("can't seek")
+}
+
+func TestResumeErrors(t *testing.T) {
+	_, err := csv.NewResumingReader(failingSeeker{strings.NewReader("a\n")}, csv.Checkpoint{Offset: 2, Record: 1, Line: 2})
+	assert.EqualError(t, err, "can't seek")
+
+	// The header is read again
+	_, err = csv.NewResumingReader(strings.NewReader("a,a\n1,2\n"), csv.Checkpoint{Offset: 4, Record: 1, Line: 2}, csv.UniqueHeader())
+	assert.True(t, errors.Is(err, csv.ErrDuplicateColumn))
+}
+
+func ExampleNewResumingReader() {
+	in := strings.NewReader("name,age\nAlice,34\nBob,27\nCarol,51\n")
+
+	// Save a checkpoint after each row. A real job would save it somewhere safe from time to time
+	var cp csv.Checkpoint
+	r := csv.NewReader(in, csv.UseHeader(), csv.SkipBlankLines())
+	for row, err := range r.Rows() {
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Println("read", row.Text(0))
+		cp = r.Checkpoint()
+		if row.Text(0) == "Bob" {
+			// The job is stopped here
+			break
+		}
+	}
+
+	// When the job restarts it carries on from the checkpoint
+	r, err := csv.NewResumingReader(in, cp, csv.UseHeader(), csv.SkipBlankLines())
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for row, err := range r.Rows() {
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Println("resumed", row.Text(0))
+	}
+
+	// Output: read Alice
+	// read Bob
+	// resumed Carol
+}
@@ -0,0 +1,144 @@
+package csv
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+)
+
+// decompressor holds the readers SetCompressedInput uses, so they can be re-used for the next input
+type decompressor struct {
+	br *bufio.Reader
+	gz *gzip.Reader
+	zl io.ReadCloser
+}
+
+// NewDecompressingReader creates a Reader that reads from in, which may be compressed with gzip, zlib or
+// bzip2. The compression format is detected from the start of the input. Input that doesn't start with a
+// recognised header is read as is. An error is returned if the input can't be read or the compression header
+// is invalid.
+func NewDecompressingReader(in io.Reader, opts ...ReaderOption) (*Reader, error) {
+	r := NewReader(nil, opts...)
+	if err := r.SetCompressedInput(in); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// SetCompressedInput is SetInput for input that may be compressed with gzip, zlib or bzip2, as for
+// NewDecompressingReader. As with SetInput the Reader's buffers are re-used, as are the gzip and zlib readers.
+func (r *Reader) SetCompressedInput(in io.Reader) error {
+	d := r.decompressor
+	if d == nil {
+		d = &decompressor{}
+		r.decompressor = d
+	}
+
+	if d.br == nil {
+		d.br = bufio.NewReaderSize(in, 4096)
+	} else {
+		d.br.Reset(in)
+	}
+	// Short input is fine: it just can't be compressed
+	magic, err := d.br.Peek(10)
+	if err != nil && err != io.EOF {
+		return err
+	}
+	err = nil
+
+	var src io.Reader = d.br
+	switch {
+	case isGzip(magic):
+		if d.gz == nil {
+			d.gz, err = gzip.NewReader(d.br)
+		} else {
+			err = d.gz.Reset(d.br)
+		}
+		src = d.gz
+	case isZlib(magic) && (magic[1] != '^' || d.inflates()):
+		if d.zl == nil {
+			d.zl, err = zlib.NewReader(d.br)
+		} else {
+			err = d.zl.(zlib.Resetter).Reset(d.br, nil)
+		}
+		src = d.zl
+	case isBzip2(magic):
+		src = bzip2.NewReader(d.br)
+	}
+	if err != nil {
+		return err
+	}
+
+	r.SetInput(src)
+	return nil
+}
+
+// isGzip checks for the gzip magic number followed by the deflate method
+func isGzip(magic []byte) bool {
+	return len(magic) >= 3 && magic[0] == 0x1f && magic[1] == 0x8b && magic[2] == 8
+}
+
+// isZlib checks for the headers written for deflate with a 32KB window, which is what zlib implementations
+// use. The second byte depends on the compression level. Many more headers are valid, but they include
+// plain text such as "H," or "80", so we don't accept them. The header for the faster compression levels is
+// "x^", which is also plain text, so that must be checked further with inflates.
+func isZlib(magic []byte) bool {
+	if len(magic) < 2 || magic[0] != 0x78 {
+		return false
+	}
+	switch magic[1] {
+	case 0x01, 0x5e, 0x9c, 0xda:
+		return true
+	}
+	return false
+}
+
+// inflates reports whether the start of the input decompresses as zlib. Data that isn't compressed will
+// almost always fail to decompress well before the end of the sample. If the sample is the whole input it
+// must decompress completely, including the checksum.
+func (d *decompressor) inflates() bool {
+	sample, err := d.br.Peek(512)
+	whole := err == io.EOF
+	zr, err := zlib.NewReader(bytes.NewReader(sample))
+	if err != nil {
+		return false
+	}
+	_, err = io.Copy(io.Discard, zr)
+	return err == nil || (!whole && err == io.ErrUnexpectedEOF)
+}
+
+// isBzip2 checks for the bzip2 stream header, followed by the magic number that starts a block or the one
+// that ends the stream.
+func isBzip2(magic []byte) bool {
+	if len(magic) < 10 || !bytes.HasPrefix(magic, []byte("BZh")) || magic[3] < '1' || magic[3] > '9' {
+		return false
+	}
+	block := magic[4:]
+	return bytes.Equal(block, []byte{0x31, 0x41, 0x59, 0x26, 0x53, 0x59}) ||
+		bytes.Equal(block, []byte{0x17, 0x72, 0x45, 0x38, 0x50, 0x90})
+}
+
+// NewGzipWriter creates a Writer that writes gzip compressed CSV to w. Call Close once you are done to write
+// any buffered lines and finish the compressed stream.
+func NewGzipWriter(w io.Writer, opts ...WriterOption) *Writer {
+	gz := gzip.NewWriter(w)
+	wr := NewWriter(gz, opts...)
+	wr.closer = gz
+	return wr
+}
+
+// Close writes any complete lines that are held in the buffer, as for Flush. If the Writer compresses its
+// output Close then finishes the compressed stream. It does not close the underlying io.Writer.
+func (w *Writer) Close() error {
+	err := w.Flush()
+	if w.closer != nil {
+		if cerr := w.closer.Close(); err == nil {
+			err = cerr
+		}
+		w.closer = nil
+	}
+	return err
+}
@@ -0,0 +1,152 @@
+package csv_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/philpearl/csv"
+	"github.com/stretchr/testify/assert"
+)
+
+const compressText = "name,n\nhat,1\n\"coat, big\",2\n"
+
+var compressRows = [][]string{{"name", "n"}, {"hat", "1"}, {"coat, big", "2"}, {""}}
+
+func gzipped(t *testing.T, s string) []byte {
+	var b bytes.Buffer
+	w := gzip.NewWriter(&b)
+	_, err := w.Write([]byte(s))
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+	return b.Bytes()
+}
+
+func zlibbed(t *testing.T, s string, level int) []byte {
+	var b bytes.Buffer
+	w, err := zlib.NewWriterLevel(&b, level)
+	assert.NoError(t, err)
+	_, err = w.Write([]byte(s))
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+	return b.Bytes()
+}
+
+func bzipped(t *testing.T) []byte {
+	// compressText compressed with bzip2. The standard library can't write bzip2
+	b, err := hex.DecodeString("425a6839314159265359824d92e500000bd9800010500430003ae38400200031434d30004434da86466a66408629beb268cc025ea9d4aa7c5dc914e1424209364b94")
+	assert.NoError(t, err)
+	return b
+}
+
+func TestDecompressingReader(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []byte
+		exp  [][]string
+	}{
+		{name: "gzip", in: gzipped(t, compressText), exp: compressRows},
+		{name: "zlib fastest", in: zlibbed(t, compressText, zlib.BestSpeed), exp: compressRows},
+		{name: "zlib fast", in: zlibbed(t, compressText, 3), exp: compressRows},
+		{name: "zlib default", in: zlibbed(t, compressText, zlib.DefaultCompression), exp: compressRows},
+		{name: "zlib best", in: zlibbed(t, compressText, zlib.BestCompression), exp: compressRows},
+		{name: "bzip2", in: bzipped(t), exp: compressRows},
+		{name: "plain", in: []byte(compressText), exp: compressRows},
+		{name: "plain like zlib", in: []byte("H,80\n80,H"), exp: [][]string{{"H", "80"}, {"80", "H"}}},
+		{name: "plain like zlib fast", in: []byte("x^2,y\n1,2"), exp: [][]string{{"x^2", "y"}, {"1", "2"}}},
+		{name: "plain like zlib fast short", in: []byte("x^"), exp: [][]string{{"x^"}}},
+		{name: "plain like bzip2", in: []byte("BZh9,x"), exp: [][]string{{"BZh9", "x"}}},
+		{name: "short", in: []byte("\x1f"), exp: [][]string{{"\x1f"}}},
+		{name: "empty", in: nil, exp: [][]string{{""}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r, err := csv.NewDecompressingReader(bytes.NewReader(test.in))
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, test.exp, readAll(t, r))
+		})
+	}
+}
+
+func TestDecompressingReaderLargeZlib(t *testing.T) {
+	// The compressed data is longer than the sample we check to see if it's zlib
+	var b strings.Builder
+	for i := range 1000 {
+		fmt.Fprintf(&b, "%d,%x\n", i, i*i*7919)
+	}
+	in := zlibbed(t, b.String(), 3)
+	assert.True(t, len(in) > 512)
+
+	r, err := csv.NewDecompressingReader(bytes.NewReader(in))
+	assert.NoError(t, err)
+	rows := readAll(t, r)
+	assert.Equal(t, 1001, len(rows))
+	assert.Equal(t, []string{"999", fmt.Sprintf("%x", 999*999*7919)}, rows[999])
+}
+
+func TestDecompressingReaderBadHeader(t *testing.T) {
+	_, err := csv.NewDecompressingReader(strings.NewReader("\x1f\x8b\x08\x00"))
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+}
+
+func TestSetCompressedInput(t *testing.T) {
+	r := csv.NewReader(nil, csv.UseHeader())
+	inputs := [][]byte{
+		gzipped(t, compressText),
+		[]byte(compressText),
+		gzipped(t, compressText),
+		zlibbed(t, compressText, zlib.DefaultCompression),
+		bzipped(t),
+		zlibbed(t, compressText, zlib.BestSpeed),
+	}
+	for _, in := range inputs {
+		assert.NoError(t, r.SetCompressedInput(bytes.NewReader(in)))
+		// The header is read again for each input
+		assert.Equal(t, compressRows[1:], readAll(t, r))
+	}
+}
+
+func TestGzipWriter(t *testing.T) {
+	var b bytes.Buffer
+	w := csv.NewGzipWriter(&b, csv.BufferSize(1024))
+	for _, row := range compressRows[:3] {
+		for _, cell := range row {
+			w.String(cell)
+		}
+		assert.NoError(t, w.LineComplete())
+	}
+	// Nothing is written until we close the Writer
+	assert.Zero(t, b.Len())
+	assert.NoError(t, w.Close())
+
+	gz, err := gzip.NewReader(&b)
+	assert.NoError(t, err)
+	data, err := io.ReadAll(gz)
+	assert.NoError(t, err)
+	assert.Equal(t, compressText, string(data))
+}
+
+func TestGzipWriterError(t *testing.T) {
+	w := csv.NewGzipWriter(failingWriter{})
+	w.String("a")
+	// gzip writes its header with the first data, and remembers the error
+	assert.EqualError(t, w.LineComplete(), "disk full")
+	assert.EqualError(t, w.Close(), "disk full")
+}
+
+func TestWriterClose(t *testing.T) {
+	var b bytes.Buffer
+	w := csv.NewWriter(&b, csv.BufferSize(1024))
+	w.String("a")
+	assert.NoError(t, w.LineComplete())
+	assert.NoError(t, w.Close())
+	assert.Equal(t, "a\n", b.String())
+}
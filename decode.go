@@ -0,0 +1,180 @@
+package csv
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"unsafe"
+)
+
+// Decoder reads rows from a Reader into structs. The first row of the input is taken to be a header, and
+// columns are matched to struct fields by name. Header options such as CaseInsensitiveHeader and
+// RequireColumns set on the Reader apply. The name of a field is taken from its `csv:"name"` tag, or
+// is the field name if there is no tag. Fields tagged `csv:"-"` are ignored. The fields of embedded structs
+// are treated as if they were fields of the outer struct.
+//
+// Fields may be strings, []byte, bools, ints, uints, floats, pointers to these, or types that implement
+// encoding.TextUnmarshaler. Pointer fields are set to nil if the cell is empty, except that a quoted empty
+// cell sets a string or []byte pointer field to point to an empty value (see IsNull). Fields tagged
+// with omitempty, as in `csv:"name,omitempty"`, are set to their zero value. Fields whose column is missing
+// from a short row are also set to their zero value. Blank lines are skipped.
+type Decoder struct {
+	r *Reader
+
+	// columns maps cells to fields for typ. header tells us which header of the Reader this was calculated
+	// for
+	typ     reflect.Type
+	header  int
+	columns []column
+}
+
+// column connects a cell in the CSV to a struct field
+type column struct {
+	cell  int
+	field *field
+}
+
+// NewDecoder creates a Decoder that reads rows from r. It turns on UseHeader for r.
+func NewDecoder(r *Reader) *Decoder {
+	r.useHeader = true
+	return &Decoder{r: r}
+}
+
+// Decode reads the next row from the CSV into v, which must be a non-nil pointer to a struct. It returns
+// io.EOF when there are no more rows. Decoding into the same struct again re-uses any []byte fields and
+// pointers already present, so does not allocate other than to create strings.
+func (d *Decoder) Decode(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("cannot decode into %T: need a non-nil pointer to a struct", v)
+	}
+	rv = rv.Elem()
+
+	if err := d.scan(); err != nil {
+		return err
+	}
+	if rv.Type() != d.typ || d.header != d.r.headerCount {
+		d.plan(rv.Type())
+	}
+
+	for i := range d.columns {
+		c := &d.columns[i]
+		if c.cell >= d.r.Len() {
+			// Short row. The field mustn't keep a value from an earlier row
+			if fv := fieldByIndex(rv, c.field.index, false); fv.IsValid() {
+				fv.SetZero()
+			}
+			continue
+		}
+		// Empty cells don't cause nil embedded struct pointers to be allocated
+		fv := fieldByIndex(rv, c.field.index, !d.r.IsEmpty(c.cell))
+		if !fv.IsValid() {
+			continue
+		}
+		if err := d.decodeCell(fv, c.field, c.cell); err != nil {
+			return d.r.cellError(c.cell, err)
+		}
+	}
+	return nil
+}
+
+// scan reads the next row, skipping blank lines
+func (d *Decoder) scan() error {
+	for {
+		if err := d.r.Scan(); err != nil {
+			return err
+		}
+		if d.r.Len() > 1 || !d.r.IsNull(0) {
+			return nil
+		}
+	}
+}
+
+// plan works out which cells map to which fields of struct type t
+func (d *Decoder) plan(t reflect.Type) {
+	fields := typeFields(t)
+	d.typ = t
+	d.header = d.r.headerCount
+	d.columns = d.columns[:0]
+	for i := range fields {
+		f := &fields[i]
+		if cell, ok := d.r.Index(f.name); ok {
+			d.columns = append(d.columns, column{cell: cell, field: f})
+		}
+	}
+}
+
+// isNull returns true if cell should set pointer field f to nil. An empty cell only sets a string or []byte
+// field to nil if it is not quoted, as a quoted empty cell is a valid empty string. Any empty cell sets other
+// types to nil.
+func (d *Decoder) isNull(f *field, cell int) bool {
+	if f.unmarshaler {
+		return d.r.IsEmpty(cell)
+	}
+	switch f.elem.Kind() {
+	case reflect.String:
+		return d.r.IsNull(cell)
+	case reflect.Slice:
+		if f.elem.Elem().Kind() == reflect.Uint8 {
+			return d.r.IsNull(cell)
+		}
+	}
+	return d.r.IsEmpty(cell)
+}
+
+func (d *Decoder) decodeCell(fv reflect.Value, f *field, cell int) error {
+	if f.omitEmpty && d.r.IsEmpty(cell) || f.ptr && d.isNull(f, cell) {
+		fv.SetZero()
+		return nil
+	}
+	if f.ptr {
+		if fv.IsNil() {
+			fv.Set(reflect.New(f.elem))
+		}
+		fv = fv.Elem()
+	}
+
+	raw := d.r.Raw(cell)
+	if f.unmarshaler {
+		return fv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText(raw)
+	}
+
+	s := *(*string)(unsafe.Pointer(&raw))
+	switch f.elem.Kind() {
+	case reflect.String:
+		fv.SetString(d.r.Text(cell))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, f.elem.Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, f.elem.Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		fl, err := strconv.ParseFloat(s, f.elem.Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(fl)
+	case reflect.Slice:
+		if f.elem.Elem().Kind() != reflect.Uint8 {
+			return fmt.Errorf("unsupported type %s", f.elem)
+		}
+		fv.SetBytes(append(fv.Bytes()[:0], raw...))
+	default:
+		return fmt.Errorf("unsupported type %s", f.elem)
+	}
+	return nil
+}
@@ -0,0 +1,264 @@
+package csv_test
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/philpearl/csv"
+	"github.com/stretchr/testify/assert"
+)
+
+type decodeEmbedded struct {
+	Colour string `csv:"colour"`
+	Weight int    `csv:"weight"`
+}
+
+type DecodePtrEmbedded struct {
+	Size uint16 `csv:"size"`
+}
+
+type decodeRow struct {
+	decodeEmbedded
+	*DecodePtrEmbedded
+	Name     string    `csv:"name"`
+	Count    int64     `csv:"count"`
+	Small    int8      `csv:"small"`
+	Price    float64   `csv:"price"`
+	Ratio    float32   `csv:"ratio"`
+	OK       bool      `csv:"ok"`
+	Data     []byte    `csv:"data"`
+	Opt      *int      `csv:"opt"`
+	When     time.Time `csv:"when"`
+	Ignored  string    `csv:"-"`
+	Weight   int       `csv:"weight"`
+	Missing  string    `csv:"missing"`
+	Untagged string
+}
+
+func TestDecode(t *testing.T) {
+	in := `name,count,small,price,ratio,ok,data,opt,when,colour,weight,size,Untagged,Ignored
+hat,12,-3,1.25,0.5,true,abc,7,2024-01-02T03:04:05Z,red,42,9,u,x
+"coat, big",-1,0,2,3,false,,,2024-01-02T03:04:05Z,,1,0,,y
+`
+	d := csv.NewDecoder(csv.NewReader(strings.NewReader(in)))
+
+	var rows []decodeRow
+	for {
+		var row decodeRow
+		err := d.Decode(&row)
+		if err == io.EOF {
+			break
+		}
+		if !assert.NoError(t, err) {
+			return
+		}
+		rows = append(rows, row)
+	}
+
+	seven := 7
+	when := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	assert.Equal(t, []decodeRow{
+		{
+			decodeEmbedded:    decodeEmbedded{Colour: "red"},
+			DecodePtrEmbedded: &DecodePtrEmbedded{Size: 9},
+			Name:              "hat",
+			Count:             12,
+			Small:             -3,
+			Price:             1.25,
+			Ratio:             0.5,
+			OK:                true,
+			Data:              []byte("abc"),
+			Opt:               &seven,
+			When:              when,
+			Weight:            42,
+			Untagged:          "u",
+		},
+		{
+			DecodePtrEmbedded: &DecodePtrEmbedded{},
+			Name:              "coat, big",
+			Count:             -1,
+			Price:             2,
+			Ratio:             3,
+			When:              when,
+			Weight:            1,
+		},
+	}, rows)
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		v    any
+		err  string
+	}{
+		{
+			name: "not a pointer",
+			in:   "a\n1",
+			v:    struct{}{},
+			err:  "cannot decode into struct {}: need a non-nil pointer to a struct",
+		},
+		{
+			name: "overflow",
+			in:   "small\n300",
+			v:    &decodeRow{},
+			err:  "record 2, cell 0 \"small\" (line 2, offset 6): strconv.ParseInt: parsing \"300\": value out of range",
+		},
+		{
+			name: "negative uint",
+			in:   "size\n-1",
+			v:    &decodeRow{},
+			err:  "record 2, cell 0 \"size\" (line 2, offset 5): strconv.ParseUint: parsing \"-1\": invalid syntax",
+		},
+		{
+			name: "bad time",
+			in:   "when\nyesterday",
+			v:    &decodeRow{},
+			err:  "record 2, cell 0 \"when\" (line 2, offset 5): parsing time \"yesterday\" as \"2006-01-02T15:04:05Z07:00\": cannot parse \"yesterday\" as \"2006\"",
+		},
+		{
+			name: "unsupported",
+			in:   "A\n1",
+			v:    &struct{ A []int }{},
+			err:  "record 2, cell 0 \"A\" (line 2, offset 2): unsupported type []int",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			d := csv.NewDecoder(csv.NewReader(strings.NewReader(test.in)))
+			assert.EqualError(t, d.Decode(test.v), test.err)
+		})
+	}
+}
+
+func TestDecodeShortRow(t *testing.T) {
+	type row struct {
+		A int
+		B string
+		C *int
+	}
+	d := csv.NewDecoder(csv.NewReader(strings.NewReader("A,B,C\n1,2,3\n4\n")))
+
+	// Decoding into the same struct, the short row must not keep values from the row before
+	var v row
+	assert.NoError(t, d.Decode(&v))
+	three := 3
+	assert.Equal(t, row{A: 1, B: "2", C: &three}, v)
+	assert.NoError(t, d.Decode(&v))
+	assert.Equal(t, row{A: 4}, v)
+	assert.Equal(t, io.EOF, d.Decode(&v))
+}
+
+func TestDecodeQuotedEmptyLine(t *testing.T) {
+	// A line that is a quoted empty string is a row, not a blank line
+	d := csv.NewDecoder(csv.NewReader(strings.NewReader("A\nx\n\n\"\"\ny\n")))
+	var got []string
+	for {
+		var v struct{ A string }
+		err := d.Decode(&v)
+		if err == io.EOF {
+			break
+		}
+		if !assert.NoError(t, err) {
+			return
+		}
+		got = append(got, v.A)
+	}
+	assert.Equal(t, []string{"x", "", "y"}, got)
+}
+
+// Node embeds a pointer to itself
+type Node struct {
+	*Node
+	X int
+}
+
+func TestDecodeRecursiveEmbedded(t *testing.T) {
+	d := csv.NewDecoder(csv.NewReader(strings.NewReader("X\n1\n")))
+	var v Node
+	assert.NoError(t, d.Decode(&v))
+	assert.Equal(t, Node{X: 1}, v)
+}
+
+func TestDecodeReuseAllocs(t *testing.T) {
+	// The repeating input means the header row matches every other row
+	type row struct {
+		A int     `csv:"1"`
+		B float64 `csv:"2.5"`
+		C []byte  `csv:"hat"`
+		D *uint32 `csv:"17"`
+	}
+
+	r := csv.NewReader(&repeatReader{content: []byte("1, 2.5, hat, 17\n")})
+	d := csv.NewDecoder(r)
+
+	var v row
+	// The first call reads the header and allocates the []byte and pointer
+	assert.NoError(t, d.Decode(&v))
+	assert.NoError(t, d.Decode(&v))
+
+	allocs := testing.AllocsPerRun(100, func() {
+		if err := d.Decode(&v); err != nil {
+			t.Fatal(err)
+		}
+	})
+	assert.Zero(t, allocs)
+	assert.Equal(t, 1, v.A)
+	assert.Equal(t, "hat", string(v.C))
+	assert.Equal(t, uint32(17), *v.D)
+}
+
+func ExampleDecoder() {
+	in := `name,age,height
+Alice,34,1.68
+Bob,27,1.82
+`
+	type person struct {
+		Name   string  `csv:"name"`
+		Age    int     `csv:"age"`
+		Height float64 `csv:"height"`
+	}
+
+	d := csv.NewDecoder(csv.NewReader(strings.NewReader(in)))
+	for {
+		var p person
+		if err := d.Decode(&p); err != nil {
+			if err == io.EOF {
+				break
+			}
+			log.Fatal(err)
+		}
+		fmt.Printf("%+v\n", p)
+	}
+
+	// Output: {Name:Alice Age:34 Height:1.68}
+	// {Name:Bob Age:27 Height:1.82}
+}
+
+func BenchmarkDecode(b *testing.B) {
+	type row struct {
+		A string  `csv:"cheese"`
+		D int     `csv:"99"`
+		G float64 `csv:"12.3"`
+	}
+
+	content := []byte(`cheese, feet, lemon, 99, 1002, 1298, 12.3, 17, 11, whale
+`)
+	d := csv.NewDecoder(csv.NewReader(&repeatReader{content: content}))
+
+	b.SetBytes(int64(len(content)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	var v row
+	for i := 0; i < b.N; i++ {
+		if err := d.Decode(&v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
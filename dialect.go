@@ -0,0 +1,208 @@
+package csv
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// Dialect describes the punctuation used in a CSV file. Zero fields take their default values, so
+// Dialect{Comma: '\t'} describes a tab-separated file. A Dialect can be passed to both NewReader and
+// NewWriter.
+type Dialect struct {
+	// Comma is the field delimiter. It defaults to ','.
+	Comma byte
+	// Quote is the character used to quote cells. It defaults to '"'.
+	Quote byte
+	// LineTerminator is written by Writer at the end of each line. It defaults to "\n". Reader always
+	// accepts both "\n" and "\r\n".
+	LineTerminator string
+}
+
+// DefaultDialect is the dialect used if none is specified: comma separated, double-quote quoted and newline
+// terminated.
+var DefaultDialect = Dialect{Comma: ',', Quote: '"', LineTerminator: "\n"}
+
+// ReaderOption configures a Reader. Options are passed to NewReader.
+type ReaderOption interface {
+	applyReader(r *Reader)
+}
+
+// WriterOption configures a Writer. Options are passed to NewWriter.
+type WriterOption interface {
+	applyWriter(w *Writer)
+}
+
+// Strict makes the Reader reject input that does not conform to RFC 4180. Quotes may only appear in quoted
+// cells, where they must be doubled, a closing quote must be followed by a delimiter or the end of the line,
+// and carriage returns outside quoted cells must be part of a "\r\n" line ending. Leading white space is
+// kept as part of the cell. These rules can be relaxed by following Strict with LazyQuotes or
+// TrimLeadingSpace.
+//
+// Without Strict the Reader accepts quotes within unquoted cells, discards leading white space and white
+// space following a closing quote, and treats a lone '\r' as part of the cell.
+func Strict() ReaderOption {
+	return readerOptionFunc(func(r *Reader) {
+		r.strict = true
+		r.trimSpace = false
+		r.bareQuotes = false
+		r.lazyQuotes = false
+	})
+}
+
+// StdlibCompatible makes the Reader follow the rules of encoding/csv. These are the rules of Strict, except
+// that a lone '\r' is part of the cell, "\r\n" within a quoted cell is read as "\n", blank lines are
+// skipped, and a byte order mark at the start of the input is not skipped. The rules can be relaxed by
+// following StdlibCompatible with LazyQuotes or TrimLeadingSpace, which then behave as the fields of the same
+// name in encoding/csv. Note that TrimLeadingSpace only discards spaces and tabs, whereas encoding/csv
+// discards any Unicode white space.
+func StdlibCompatible() ReaderOption {
+	return readerOptionFunc(func(r *Reader) {
+		Strict().applyReader(r)
+		r.compat = true
+		r.skipBlank = true
+	})
+}
+
+// LazyQuotes relaxes the rules on quotes, as for LazyQuotes in encoding/csv. A quote may appear in an
+// unquoted cell, and a quote within a quoted cell that is not followed by a delimiter or the end of the line
+// is treated as part of the cell. An unterminated quoted cell at the end of the input is accepted.
+func LazyQuotes() ReaderOption {
+	return readerOptionFunc(func(r *Reader) {
+		r.lazyQuotes = true
+		r.bareQuotes = true
+	})
+}
+
+// TrimLeadingSpace controls whether spaces and tabs at the start of a cell are discarded. They are
+// discarded by default, and kept if Strict is used.
+func TrimLeadingSpace(trim bool) ReaderOption {
+	return readerOptionFunc(func(r *Reader) {
+		r.trimSpace = trim
+	})
+}
+
+// KeepWhitespace makes the Reader keep white space in cells verbatim. By default spaces and tabs at the start
+// of a cell and after the closing quote of a quoted cell are discarded. With KeepWhitespace a cell that
+// starts with white space is not a quoted cell, so any quotes within it are kept as they are.
+func KeepWhitespace() ReaderOption {
+	return readerOptionFunc(func(r *Reader) {
+		r.trimSpace = false
+		r.keepSpace = true
+	})
+}
+
+// Comment makes the Reader skip lines that start with c. The comment character must be the first character
+// on the line, and must not be a line ending, the delimiter or the quote character. Comment lines are still
+// counted when reporting line numbers.
+func Comment(c byte) ReaderOption {
+	return readerOptionFunc(func(r *Reader) {
+		r.comment = c
+	})
+}
+
+// SkipBlankLines makes the Reader skip empty lines rather than returning them as rows containing a single
+// empty cell. A line containing only white space is not empty.
+func SkipBlankLines() ReaderOption {
+	return readerOptionFunc(func(r *Reader) {
+		r.skipBlank = true
+	})
+}
+
+// FieldsPerRecord makes the Reader check the number of cells in each record. If n is positive every record
+// must have n cells. If n is zero every record must have the same number of cells as the first, which is the
+// header if one is used. If n is negative no check is made. A record with the wrong number of cells causes
+// Scan to return a ParseError wrapping ErrFieldCount, but the record is still available. Blank lines are
+// records with a single empty cell, so are checked too unless SkipBlankLines is used. The empty record after a
+// line ending at the end of the input is not checked.
+func FieldsPerRecord(n int) ReaderOption {
+	return readerOptionFunc(func(r *Reader) {
+		r.checkFields = n >= 0
+		r.fieldsPerRecord = max(n, 0)
+	})
+}
+
+// MaxCellSize limits the size of each cell to n bytes. Scan returns a ParseError wrapping ErrCellTooLarge if
+// a cell is larger. The limit protects against input, such as a quote that is never closed, that would make
+// the Reader use unlimited memory. The size is checked as the cell is read, so the Reader doesn't read much
+// more than n bytes of a cell before it fails. n is zero by default, which means there's no limit.
+func MaxCellSize(n int) ReaderOption {
+	return readerOptionFunc(func(r *Reader) {
+		r.maxCellSize = n
+	})
+}
+
+// MaxRowSize limits the size of each row to n bytes of input, including the delimiters, quotes and line
+// ending. Scan returns a ParseError wrapping ErrRowTooLarge if a row is larger. It is checked in the same way
+// as MaxCellSize. n is zero by default, which means there's no limit.
+func MaxRowSize(n int) ReaderOption {
+	return readerOptionFunc(func(r *Reader) {
+		r.maxRowSize = n
+	})
+}
+
+// MaxCellsPerRow limits the number of cells in each row to n. Scan returns a ParseError wrapping
+// ErrTooManyCells as soon as it finds a row has more. n is zero by default, which means there's no limit.
+func MaxCellsPerRow(n int) ReaderOption {
+	return readerOptionFunc(func(r *Reader) {
+		r.maxCells = n
+	})
+}
+
+type readerOptionFunc func(r *Reader)
+
+func (f readerOptionFunc) applyReader(r *Reader) { f(r) }
+
+type writerOptionFunc func(w *Writer)
+
+func (f writerOptionFunc) applyWriter(w *Writer) { f(w) }
+
+func (d Dialect) applyReader(r *Reader) {
+	d = d.withDefaults()
+	if err := d.validate(); err != nil {
+		panic(err)
+	}
+	r.delim = d.Comma
+	r.quote = d.Quote
+}
+
+func (d Dialect) applyWriter(w *Writer) {
+	d = d.withDefaults()
+	if err := d.validate(); err != nil {
+		panic(err)
+	}
+	w.delim = d.Comma
+	w.quote = d.Quote
+	w.lineTerminator = d.LineTerminator
+}
+
+func (d Dialect) withDefaults() Dialect {
+	if d.Comma == 0 {
+		d.Comma = DefaultDialect.Comma
+	}
+	if d.Quote == 0 {
+		d.Quote = DefaultDialect.Quote
+	}
+	if d.LineTerminator == "" {
+		d.LineTerminator = DefaultDialect.LineTerminator
+	}
+	return d
+}
+
+func (d Dialect) validate() error {
+	switch {
+	case d.Comma == d.Quote:
+		return fmt.Errorf("delimiter and quote must differ (both %q)", d.Comma)
+	case d.Comma == '\r' || d.Comma == '\n':
+		return fmt.Errorf("invalid delimiter %q", d.Comma)
+	case d.Quote == '\r' || d.Quote == '\n':
+		return fmt.Errorf("invalid quote %q", d.Quote)
+	case d.Comma >= utf8.RuneSelf:
+		// A byte of a multi-byte UTF-8 character would split characters apart
+		return fmt.Errorf("delimiter %#x is not an ASCII character", d.Comma)
+	case d.Quote >= utf8.RuneSelf:
+		return fmt.Errorf("quote %#x is not an ASCII character", d.Quote)
+	case d.LineTerminator != "\n" && d.LineTerminator != "\r\n":
+		return fmt.Errorf("line terminator must be \"\\n\" or \"\\r\\n\", not %q", d.LineTerminator)
+	}
+	return nil
+}
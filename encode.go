@@ -0,0 +1,127 @@
+package csv
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+)
+
+// Encoder writes structs to a Writer as CSV rows. The first call to Encode writes a header row containing
+// the column names. Columns are named and ordered in the same way as for Decoder.
+//
+// Nil pointers are written as empty cells. Fields whose tag includes omitempty, as in
+// `csv:"name,omitempty"`, are written as empty cells if they have their zero value. Float fields may set
+// the format and precision used to write them, as in `csv:"price,format=f,prec=2"`; see
+// strconv.FormatFloat. Types that implement encoding.TextMarshaler are written using MarshalText.
+type Encoder struct {
+	w      *Writer
+	typ    reflect.Type
+	fields []field
+}
+
+// NewEncoder creates an Encoder that writes rows to w.
+func NewEncoder(w *Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// Encode writes v as a row of the CSV. v must be a struct or a pointer to a struct, and every call to Encode
+// on an Encoder must pass the same type. The header row is written before the first row. If Encode returns an
+// error nothing is written for v.
+func (e *Encoder) Encode(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("cannot encode %T: need a struct or a pointer to a struct", v)
+	}
+
+	if e.typ == nil {
+		if err := e.writeHeader(rv.Type()); err != nil {
+			return err
+		}
+	} else if rv.Type() != e.typ {
+		return fmt.Errorf("cannot encode %s: Encoder is writing %s", rv.Type(), e.typ)
+	}
+
+	start := len(e.w.b)
+	for i := range e.fields {
+		f := &e.fields[i]
+		if err := e.encodeField(fieldByIndex(rv, f.index, false), f); err != nil {
+			e.w.truncate(start)
+			return fmt.Errorf("column %q: %w", f.name, err)
+		}
+	}
+	return e.w.LineComplete()
+}
+
+// writeHeader writes the header for type t. The Encoder only commits to t once the header is written.
+func (e *Encoder) writeHeader(t reflect.Type) error {
+	fields := typeFields(t)
+	for i := range fields {
+		e.w.String(fields[i].name)
+	}
+	if err := e.w.LineComplete(); err != nil {
+		return err
+	}
+	e.typ, e.fields = t, fields
+	return nil
+}
+
+func (e *Encoder) encodeField(fv reflect.Value, f *field) error {
+	if !fv.IsValid() {
+		// The field is within a nil embedded struct pointer
+		e.w.Skip()
+		return nil
+	}
+	if f.ptr {
+		if fv.IsNil() {
+			e.w.Skip()
+			return nil
+		}
+		fv = fv.Elem()
+	}
+	if f.omitEmpty && fv.IsZero() {
+		e.w.Skip()
+		return nil
+	}
+
+	if f.marshaler || f.ptrMarshaler {
+		if f.ptrMarshaler {
+			if !fv.CanAddr() {
+				// Take an addressable copy so we can call the pointer method
+				c := reflect.New(f.elem).Elem()
+				c.Set(fv)
+				fv = c
+			}
+			fv = fv.Addr()
+		}
+		text, err := fv.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return err
+		}
+		e.w.Bytes(text)
+		return nil
+	}
+
+	switch f.elem.Kind() {
+	case reflect.String:
+		e.w.String(fv.String())
+	case reflect.Bool:
+		e.w.Bool(fv.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.w.Int64(fv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		e.w.Uint64(fv.Uint())
+	case reflect.Float32, reflect.Float64:
+		e.w.FloatFormat(fv.Float(), f.format, f.prec, f.elem.Bits())
+	case reflect.Slice:
+		if f.elem.Elem().Kind() != reflect.Uint8 {
+			return fmt.Errorf("unsupported type %s", f.elem)
+		}
+		e.w.Bytes(fv.Bytes())
+	default:
+		return fmt.Errorf("unsupported type %s", f.elem)
+	}
+	return nil
+}
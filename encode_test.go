@@ -0,0 +1,268 @@
+package csv_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/philpearl/csv"
+	"github.com/stretchr/testify/assert"
+)
+
+type encodeEmbedded struct {
+	Colour string `csv:"colour"`
+}
+
+type EncodePtrEmbedded struct {
+	Size uint16 `csv:"size"`
+}
+
+type encodeRow struct {
+	Name string `csv:"name"`
+	encodeEmbedded
+	*EncodePtrEmbedded
+	Count   int64     `csv:"count"`
+	Price   float64   `csv:"price,format=f,prec=2"`
+	Ratio   float32   `csv:"ratio"`
+	OK      bool      `csv:"ok"`
+	Data    []byte    `csv:"data"`
+	Opt     *int      `csv:"opt"`
+	Zero    int       `csv:"zero,omitempty"`
+	When    time.Time `csv:"when"`
+	Ignored string    `csv:"-"`
+}
+
+type failingMarshaler struct{}
+
+func (failingMarshaler) MarshalText() ([]byte, error) { return nil, errors.New("no") }
+
+type ptrMarshaler struct{ v int }
+
+func (p *ptrMarshaler) MarshalText() ([]byte, error) { return []byte{'p', byte('0' + p.v)}, nil }
+
+func TestEncode(t *testing.T) {
+	seven := 7
+	when := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	var b bytes.Buffer
+	e := csv.NewEncoder(csv.NewWriter(&b))
+
+	assert.NoError(t, e.Encode(&encodeRow{
+		Name:              "hat",
+		encodeEmbedded:    encodeEmbedded{Colour: "red, really"},
+		EncodePtrEmbedded: &EncodePtrEmbedded{Size: 9},
+		Count:             -12,
+		Price:             1.256,
+		Ratio:             0.1,
+		OK:                true,
+		Data:              []byte("abc"),
+		Opt:               &seven,
+		Zero:              3,
+		When:              when,
+		Ignored:           "x",
+	}))
+	assert.NoError(t, e.Encode(encodeRow{Name: "coat", Price: 2}))
+
+	assert.Equal(t, `name,colour,size,count,price,ratio,ok,data,opt,zero,when
+hat,"red, really",9,-12,1.26,0.1,true,abc,7,3,2024-01-02T03:04:05Z
+coat,,,0,2.00,0,false,,,,0001-01-01T00:00:00Z
+`, b.String())
+}
+
+type conflictA struct {
+	A string
+	B string `csv:"B"`
+	C string `csv:"c"`
+}
+
+type conflictB struct {
+	A string
+	B string
+	C string `csv:"c"`
+	D string
+}
+
+type conflictDeep struct {
+	D string `csv:"D"`
+}
+
+type conflictInner struct {
+	conflictDeep
+}
+
+func TestEncodeFieldConflicts(t *testing.T) {
+	// Fields with the same name follow the rules of encoding/json
+	tests := []struct {
+		name string
+		v    any
+		exp  string
+	}{
+		{
+			// Neither A is used. The tagged B beats the untagged one. Neither c is used
+			name: "same depth",
+			v: struct {
+				conflictA
+				conflictB
+			}{conflictA{"a1", "b1", "c1"}, conflictB{"a2", "b2", "c2", "d2"}},
+			exp: "B,D\nb1,d2\n",
+		},
+		{
+			name: "shallowest wins",
+			v: struct {
+				conflictB
+				A string
+			}{conflictB{"a2", "b2", "c2", "d2"}, "a"},
+			exp: "B,c,D,A\nb2,c2,d2,a\n",
+		},
+		{
+			// The deeper D is hidden even though it's tagged
+			name: "deeper",
+			v: struct {
+				conflictB
+				conflictInner
+			}{conflictB{"a2", "b2", "c2", "d2"}, conflictInner{conflictDeep{"d3"}}},
+			exp: "A,B,c,D\na2,b2,c2,d2\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var b bytes.Buffer
+			e := csv.NewEncoder(csv.NewWriter(&b))
+			assert.NoError(t, e.Encode(test.v))
+			assert.Equal(t, test.exp, b.String())
+		})
+	}
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	seven := 7
+	in := []encodeRow{
+		{Name: "a\"b", Count: 1, Price: 3.5, Opt: &seven, When: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{Name: "line\nbreak", EncodePtrEmbedded: &EncodePtrEmbedded{Size: 3}, Data: []byte("x,y")},
+	}
+
+	var b bytes.Buffer
+	e := csv.NewEncoder(csv.NewWriter(&b))
+	for i := range in {
+		assert.NoError(t, e.Encode(&in[i]))
+	}
+
+	d := csv.NewDecoder(csv.NewReader(&b))
+	for i := range in {
+		var out encodeRow
+		assert.NoError(t, d.Decode(&out))
+		assert.Equal(t, in[i], out)
+	}
+}
+
+func TestEncodeRoundTripQuoteEmpty(t *testing.T) {
+	type row struct {
+		S *string
+		B *[]byte
+		I *int
+	}
+	var emptyBytes []byte
+	empty, zero := "", 0
+	in := []row{
+		{S: &empty, B: &emptyBytes, I: &zero},
+		{},
+	}
+
+	var b bytes.Buffer
+	e := csv.NewEncoder(csv.NewWriter(&b, csv.QuoteEmpty()))
+	for i := range in {
+		assert.NoError(t, e.Encode(&in[i]))
+	}
+	assert.Equal(t, "S,B,I\n\"\",\"\",0\n,,\n", b.String())
+
+	// The quoted empty strings are read back as empty strings, and the empty cells as nil
+	d := csv.NewDecoder(csv.NewReader(&b))
+	for i := range in {
+		var out row
+		assert.NoError(t, d.Decode(&out))
+		assert.Equal(t, in[i], out)
+	}
+}
+
+func TestEncodeRecursiveEmbedded(t *testing.T) {
+	var b bytes.Buffer
+	e := csv.NewEncoder(csv.NewWriter(&b))
+	assert.NoError(t, e.Encode(Node{X: 1}))
+	assert.Equal(t, "X\n1\n", b.String())
+}
+
+func TestEncodeMarshaler(t *testing.T) {
+	var b bytes.Buffer
+	e := csv.NewEncoder(csv.NewWriter(&b))
+
+	type row struct {
+		P ptrMarshaler
+	}
+	assert.NoError(t, e.Encode(row{P: ptrMarshaler{v: 1}}))
+	assert.NoError(t, e.Encode(&row{P: ptrMarshaler{v: 2}}))
+	assert.Equal(t, "P\np1\np2\n", b.String())
+}
+
+func TestEncodeErrors(t *testing.T) {
+	type row struct {
+		A int
+		F failingMarshaler
+	}
+
+	var b bytes.Buffer
+	e := csv.NewEncoder(csv.NewWriter(&b))
+	assert.EqualError(t, e.Encode(1), "cannot encode int: need a struct or a pointer to a struct")
+	assert.EqualError(t, e.Encode(row{A: 1}), "column \"F\": no")
+	assert.EqualError(t, e.Encode(struct{ B int }{}), "cannot encode struct { B int }: Encoder is writing csv_test.row")
+	assert.Equal(t, "A,F\n", b.String())
+
+	b.Reset()
+	e = csv.NewEncoder(csv.NewWriter(&b))
+	assert.EqualError(t, e.Encode(struct{ C []int }{C: []int{1}}), "column \"C\": unsupported type []int")
+	assert.Equal(t, "C\n", b.String())
+
+	// A header that can't be written doesn't fix the type
+	e = csv.NewEncoder(csv.NewWriter(failingWriter{}))
+	assert.EqualError(t, e.Encode(row{A: 1}), "disk full")
+	assert.EqualError(t, e.Encode(struct{ B int }{}), "disk full")
+}
+
+func ExampleEncoder() {
+	type person struct {
+		Name   string  `csv:"name"`
+		Age    int     `csv:"age"`
+		Height float64 `csv:"height,format=f,prec=2"`
+	}
+
+	e := csv.NewEncoder(csv.NewWriter(os.Stdout))
+	_ = e.Encode(person{Name: "Alice", Age: 34, Height: 1.68})
+	_ = e.Encode(person{Name: "Bob", Age: 27, Height: 1.8})
+
+	// Output: name,age,height
+	// Alice,34,1.68
+	// Bob,27,1.80
+}
+
+func BenchmarkEncode(b *testing.B) {
+	type row struct {
+		A string  `csv:"a"`
+		B int     `csv:"b"`
+		C float64 `csv:"c"`
+		D *int    `csv:"d"`
+	}
+
+	var buf bytes.Buffer
+	e := csv.NewEncoder(csv.NewWriter(&buf))
+	v := row{A: "hatah", B: 37, C: 1.382}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := e.Encode(&v); err != nil {
+			b.Fatal(err)
+		}
+		buf.Reset()
+	}
+}
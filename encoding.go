@@ -0,0 +1,212 @@
+package csv
+
+import (
+	"bytes"
+	"io"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// bom is the UTF-8 byte order mark. Reader skips it if it is at the start of the input, and Writer writes it
+// if WriteBOM is used.
+var bom = []byte{0xEF, 0xBB, 0xBF}
+
+// Encoding converts input in some character encoding to UTF-8. Pass one to InputEncoding to read input that
+// is not UTF-8. The encodings in golang.org/x/text can be used by wrapping their decoders, as in
+//
+//	func(r io.Reader) io.Reader { return charmap.ISO8859_15.NewDecoder().Reader(r) }
+type Encoding func(r io.Reader) io.Reader
+
+// These are the encodings we support without further dependencies. Invalid input is replaced by
+// utf8.RuneError.
+var (
+	// UTF16 reads UTF-16, detecting the byte order from a byte order mark at the start of the input. If there
+	// is none it reads little-endian UTF-16, which is what Windows produces.
+	UTF16 Encoding = func(r io.Reader) io.Reader { return newTranscoder(r, &utf16Decoder{}) }
+	// UTF16LE reads little-endian UTF-16.
+	UTF16LE Encoding = func(r io.Reader) io.Reader { return newTranscoder(r, &utf16Decoder{order: littleEndian}) }
+	// UTF16BE reads big-endian UTF-16.
+	UTF16BE Encoding = func(r io.Reader) io.Reader { return newTranscoder(r, &utf16Decoder{order: bigEndian}) }
+	// Windows1252 reads the Windows-1252 code page used by Western European versions of Windows.
+	Windows1252 Encoding = func(r io.Reader) io.Reader { return newTranscoder(r, &windows1252) }
+	// ISO8859_1 reads ISO 8859-1, also known as Latin-1.
+	ISO8859_1 Encoding = func(r io.Reader) io.Reader { return newTranscoder(r, &latin1) }
+)
+
+// InputEncoding makes the Reader convert its input from e to UTF-8 as it reads it. Offsets in positions and
+// errors are then offsets within the converted input.
+func InputEncoding(e Encoding) ReaderOption {
+	return readerOptionFunc(func(r *Reader) {
+		r.encoding = e
+		r.r = e(r.r)
+	})
+}
+
+// WriteBOM makes the Writer write a UTF-8 byte order mark at the start of the output. Excel needs this to
+// recognise that a CSV file is UTF-8.
+func WriteBOM() WriterOption {
+	return writerOptionFunc(func(w *Writer) {
+		w.b = append(w.b[:0], bom...)
+		w.done = len(w.b)
+	})
+}
+
+// skipBOM skips a UTF-8 byte order mark at the start of the input. The line starts after it.
+func (r *Reader) skipBOM() error {
+	b, err := r.peek(len(bom))
+	if bytes.Equal(b, bom) {
+		r.pos += len(bom)
+		r.lineOffset = r.bufOffset + int64(r.pos)
+		return nil
+	}
+	if err != nil && err != io.EOF {
+		return err
+	}
+	return nil
+}
+
+// decoder converts src to UTF-8, appending the result to dst. It returns the number of bytes of src it has
+// consumed. Bytes that aren't consumed are passed again along with more input. If eof is set there is no
+// more input, so all of src should be consumed.
+type decoder interface {
+	decode(dst, src []byte, eof bool) ([]byte, int)
+}
+
+// transcoder is an io.Reader that converts its input to UTF-8 using a decoder.
+type transcoder struct {
+	r   io.Reader
+	d   decoder
+	err error
+
+	// in holds input that has not been decoded yet. out[outPos:] holds output that has not been read yet.
+	in     []byte
+	out    []byte
+	outPos int
+}
+
+func newTranscoder(r io.Reader, d decoder) *transcoder {
+	return &transcoder{
+		r:  r,
+		d:  d,
+		in: make([]byte, 0, 4096),
+	}
+}
+
+func (t *transcoder) Read(p []byte) (int, error) {
+	for t.outPos == len(t.out) {
+		if t.err != nil {
+			return 0, t.err
+		}
+		t.fill()
+	}
+	n := copy(p, t.out[t.outPos:])
+	t.outPos += n
+	return n, nil
+}
+
+// fill reads more input and decodes as much as it can
+func (t *transcoder) fill() {
+	n, err := t.r.Read(t.in[len(t.in):cap(t.in)])
+	t.in = t.in[:len(t.in)+n]
+	t.err = err
+
+	var used int
+	t.out, used = t.d.decode(t.out[:0], t.in, err != nil)
+	t.outPos = 0
+	t.in = t.in[:copy(t.in, t.in[used:])]
+}
+
+type byteOrder byte
+
+const (
+	unknownOrder byteOrder = iota
+	littleEndian
+	bigEndian
+)
+
+// utf16Decoder decodes UTF-16. If the order is unknown it is set from a byte order mark.
+type utf16Decoder struct {
+	order byteOrder
+}
+
+func (d *utf16Decoder) decode(dst, src []byte, eof bool) ([]byte, int) {
+	i := 0
+	if d.order == unknownOrder {
+		if len(src) < 2 && !eof {
+			return dst, 0
+		}
+		d.order = littleEndian
+		if len(src) >= 2 && src[0] == 0xFE && src[1] == 0xFF {
+			d.order = bigEndian
+			i = 2
+		}
+		// A little-endian byte order mark is left to be decoded like any other character. Reader skips it
+		// as a UTF-8 byte order mark.
+	}
+
+	for ; i+1 < len(src); i += 2 {
+		c := d.unit(src[i:])
+		if !utf16.IsSurrogate(rune(c)) {
+			dst = utf8.AppendRune(dst, rune(c))
+			continue
+		}
+		if i+3 >= len(src) {
+			if !eof {
+				// We need the next unit to decode a surrogate pair
+				break
+			}
+			dst = utf8.AppendRune(dst, utf8.RuneError)
+			continue
+		}
+		r := utf16.DecodeRune(rune(c), rune(d.unit(src[i+2:])))
+		if r != utf8.RuneError {
+			// We've used the next unit too
+			i += 2
+		}
+		dst = utf8.AppendRune(dst, r)
+	}
+
+	if eof && i < len(src) {
+		// An odd byte at the end
+		dst = utf8.AppendRune(dst, utf8.RuneError)
+		i = len(src)
+	}
+	return dst, i
+}
+
+func (d *utf16Decoder) unit(b []byte) uint16 {
+	if d.order == bigEndian {
+		return uint16(b[0])<<8 | uint16(b[1])
+	}
+	return uint16(b[1])<<8 | uint16(b[0])
+}
+
+// charmap decodes a single-byte character set. Bytes below 0x80 are ASCII. The charmap gives the characters
+// for bytes 0x80 and above.
+type charmap [128]rune
+
+func (m *charmap) decode(dst, src []byte, eof bool) ([]byte, int) {
+	for _, c := range src {
+		if c < utf8.RuneSelf {
+			dst = append(dst, c)
+			continue
+		}
+		dst = utf8.AppendRune(dst, m[c-0x80])
+	}
+	return dst, len(src)
+}
+
+var latin1, windows1252 charmap
+
+func init() {
+	for i := range latin1 {
+		latin1[i] = rune(0x80 + i)
+	}
+	// Windows-1252 differs from Latin-1 in the range 0x80 to 0x9F. The five undefined bytes are mapped to the
+	// control characters with the same values, as in the WHATWG encoding standard.
+	windows1252 = latin1
+	copy(windows1252[:], []rune{
+		'€', 0x81, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0x8D, 'Ž', 0x8F,
+		0x90, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0x9D, 'ž', 'Ÿ',
+	})
+}
@@ -0,0 +1,170 @@
+package csv_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"unicode/utf16"
+
+	"github.com/philpearl/csv"
+	"github.com/stretchr/testify/assert"
+)
+
+func readAll(t *testing.T, r *csv.Reader) [][]string {
+	t.Helper()
+	var rows [][]string
+	for {
+		row, err := r.Read()
+		if err == io.EOF {
+			return rows
+		}
+		if !assert.NoError(t, err) {
+			return rows
+		}
+		rows = append(rows, append([]string(nil), row...))
+	}
+}
+
+func TestReadBOM(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		opts []csv.ReaderOption
+		exp  [][]string
+	}{
+		{
+			name: "bom",
+			in:   "\ufeffname,age\n\"Bob\",3",
+			exp:  [][]string{{"name", "age"}, {"Bob", "3"}},
+		},
+		{
+			name: "only bom",
+			in:   "\ufeff",
+			opts: []csv.ReaderOption{csv.SkipBlankLines()},
+		},
+		{
+			name: "bom and comment",
+			in:   "\ufeff#comment\na",
+			opts: []csv.ReaderOption{csv.Comment('#')},
+			exp:  [][]string{{"a"}},
+		},
+		{
+			name: "bom not at start",
+			in:   "a\n\ufeffb",
+			exp:  [][]string{{"a"}, {"\ufeffb"}},
+		},
+		{
+			name: "partial bom",
+			in:   "\xef\xbba",
+			exp:  [][]string{{"\xef\xbba"}},
+		},
+		{
+			name: "stdlib compatible keeps bom",
+			in:   "\ufeffa",
+			opts: []csv.ReaderOption{csv.StdlibCompatible()},
+			exp:  [][]string{{"\ufeffa"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := csv.NewReader(iotest.OneByteReader(strings.NewReader(test.in)), test.opts...)
+			assert.Equal(t, test.exp, readAll(t, r))
+		})
+	}
+}
+
+func TestReadBOMPosition(t *testing.T) {
+	r := csv.NewReader(strings.NewReader("\ufeffa,\"b\"x"))
+	err := r.Scan()
+	assert.EqualError(t, err, "record 1, cell 1 (line 1, offset 8): unexpected char x after terminating quote")
+
+	r.SetInput(strings.NewReader("\ufeffa,b"))
+	assert.NoError(t, r.Scan())
+	line, col := r.FieldPos(0)
+	assert.Equal(t, [2]int{1, 1}, [2]int{line, col})
+	assert.Equal(t, int64(3), r.CellOffset(0))
+}
+
+func encodeUTF16(s string, bigEndian bool) []byte {
+	var b []byte
+	for _, u := range utf16.Encode([]rune(s)) {
+		if bigEndian {
+			b = append(b, byte(u>>8), byte(u))
+		} else {
+			b = append(b, byte(u), byte(u>>8))
+		}
+	}
+	return b
+}
+
+func TestReadEncoding(t *testing.T) {
+	const text = "\ufeffname,city\n\"Zoë\",Zürich\n😀,€5\n"
+	exp := [][]string{{"name", "city"}, {"Zoë", "Zürich"}, {"😀", "€5"}, {""}}
+
+	tests := []struct {
+		name string
+		in   []byte
+		enc  csv.Encoding
+		exp  [][]string
+	}{
+		{name: "UTF16 little-endian", in: encodeUTF16(text, false), enc: csv.UTF16, exp: exp},
+		{name: "UTF16 big-endian", in: encodeUTF16(text, true), enc: csv.UTF16, exp: exp},
+		{name: "UTF16 no BOM", in: encodeUTF16(text[3:], false), enc: csv.UTF16, exp: exp},
+		{name: "UTF16LE", in: encodeUTF16(text, false), enc: csv.UTF16LE, exp: exp},
+		{name: "UTF16BE", in: encodeUTF16(text, true), enc: csv.UTF16BE, exp: exp},
+		{
+			name: "UTF16 invalid",
+			// An unpaired surrogate followed by an odd byte
+			in:  append(encodeUTF16("a,", false), 0x00, 0xD8, 'b', 0, 'c'),
+			enc: csv.UTF16LE,
+			exp: [][]string{{"a", "�b�"}},
+		},
+		{
+			name: "Windows1252",
+			in:   []byte("name,price\n\x93quoted\x94,\x805\nna\xefve,\x81\n"),
+			enc:  csv.Windows1252,
+			exp:  [][]string{{"name", "price"}, {"“quoted”", "€5"}, {"naïve", "\u0081"}, {""}},
+		},
+		{
+			name: "ISO8859_1",
+			in:   []byte("na\xefve,\x80\xff\n"),
+			enc:  csv.ISO8859_1,
+			exp:  [][]string{{"naïve", "\u0080ÿ"}, {""}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Reading a byte at a time means surrogate pairs are split between reads
+			r := csv.NewReader(iotest.OneByteReader(bytes.NewReader(test.in)), csv.InputEncoding(test.enc))
+			assert.Equal(t, test.exp, readAll(t, r))
+
+			r.SetInput(bytes.NewReader(test.in))
+			assert.Equal(t, test.exp, readAll(t, r))
+		})
+	}
+}
+
+func TestEncodingReader(t *testing.T) {
+	const text = "Zoë,😀,€5\n"
+	assert.NoError(t, iotest.TestReader(csv.UTF16BE(bytes.NewReader(encodeUTF16(text, true))), []byte(text)))
+	assert.NoError(t, iotest.TestReader(csv.Windows1252(strings.NewReader("Zo\xeb,\x805\n")), []byte("Zoë,€5\n")))
+}
+
+func TestWriteBOM(t *testing.T) {
+	var b bytes.Buffer
+	w := csv.NewWriter(&b, csv.WriteBOM())
+	w.String("a")
+	w.String("b")
+	assert.NoError(t, w.LineComplete())
+	w.String("c")
+	assert.NoError(t, w.LineComplete())
+	assert.Equal(t, "\ufeffa,b\nc\n", b.String())
+
+	// We read it back without the BOM
+	r := csv.NewReader(&b)
+	assert.Equal(t, [][]string{{"a", "b"}, {"c"}, {""}}, readAll(t, r))
+}
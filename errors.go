@@ -0,0 +1,140 @@
+package csv
+
+import (
+	"errors"
+	"strconv"
+)
+
+// These errors are returned wrapped in a ParseError when the input is not valid CSV.
+var (
+	// ErrBareQuote means a quote appeared in a cell that did not start with a quote. It is only returned in
+	// Strict mode.
+	ErrBareQuote = errors.New("bare quote in non-quoted cell")
+	// ErrQuote means something other than a delimiter or a line end followed the closing quote of a quoted
+	// cell. It is only returned in Strict mode.
+	ErrQuote = errors.New("extraneous or missing quote in quoted cell")
+	// ErrBareCR means a carriage return that was not part of a line ending appeared in a cell that did not
+	// start with a quote. It is only returned in Strict mode.
+	ErrBareCR = errors.New("bare \\r in non-quoted cell")
+	// ErrFieldCount means a record has the wrong number of cells. It is only returned if FieldsPerRecord is
+	// used.
+	ErrFieldCount = errors.New("wrong number of fields")
+	// ErrNoCell means a cell was requested that is beyond the end of the current row.
+	ErrNoCell = errors.New("no such cell in row")
+	// ErrCellTooLarge means a cell is larger than allowed by MaxCellSize.
+	ErrCellTooLarge = errors.New("cell too large")
+	// ErrRowTooLarge means a row is larger than allowed by MaxRowSize.
+	ErrRowTooLarge = errors.New("row too large")
+	// ErrTooManyCells means a row has more cells than allowed by MaxCellsPerRow.
+	ErrTooManyCells = errors.New("too many cells in row")
+	// ErrChunkBoundary means an Unordered ParallelReader started a chunk within a record, because the quotes
+	// in the input don't only start and end quoted cells.
+	ErrChunkBoundary = errors.New("chunk starts within a record")
+)
+
+// ParseError is returned when the CSV input is malformed, and when a cell can't be converted to the type
+// requested. It records where the problem was found. Use errors.As to retrieve it and errors.Is to test the
+// underlying error.
+type ParseError struct {
+	// Line is the line of the input where the error occurred, counting from 1. Col is the position of the
+	// error within that line in bytes, also counting from 1. StartLine is the line where the record
+	// starts. Record is the number of the record, also counting from 1. If the Reader is using a header the
+	// header is record 1.
+	Line      int
+	Col       int
+	StartLine int
+	Record    int
+	// Cell is the index of the cell within the record. Column is the name of the column from the header,
+	// if there is one.
+	Cell   int
+	Column string
+	// Offset is the byte offset in the input where the error occurred.
+	Offset int64
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	b := make([]byte, 0, 64)
+	b = append(b, "record "...)
+	b = strconv.AppendInt(b, int64(e.Record), 10)
+	b = append(b, ", cell "...)
+	b = strconv.AppendInt(b, int64(e.Cell), 10)
+	if e.Column != "" {
+		b = append(b, ' ')
+		b = strconv.AppendQuote(b, e.Column)
+	}
+	b = append(b, " (line "...)
+	b = strconv.AppendInt(b, int64(e.Line), 10)
+	b = append(b, ", offset "...)
+	b = strconv.AppendInt(b, e.Offset, 10)
+	b = append(b, "): "...)
+	b = append(b, e.Err.Error()...)
+	return string(b)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// parseError wraps err in a ParseError for the current position in the input. back is the number of bytes
+// we've read past the problem.
+func (r *Reader) parseError(err error, back int) error {
+	offset := r.bufOffset + int64(r.pos-back)
+	return &ParseError{
+		Line:      r.line,
+		Col:       int(offset-r.lineOffset) + 1,
+		StartLine: r.recordLine,
+		Record:    r.record,
+		Cell:      len(r.cells),
+		Column:    r.columnName(len(r.cells)),
+		Offset:    offset,
+		Err:       err,
+	}
+}
+
+// cellError wraps err in a ParseError for cell i of the current record. The position is that of the start
+// of the cell.
+func (r *Reader) cellError(i int, err error) error {
+	p := r.positions[i]
+	return &ParseError{
+		Line:      p.line,
+		Col:       p.column,
+		StartLine: r.recordLine,
+		Record:    r.record,
+		Cell:      i,
+		Column:    r.columnName(i),
+		Offset:    p.offset,
+		Err:       err,
+	}
+}
+
+// checkCell returns an error if cell i is not in the current row
+func (r *Reader) checkCell(i int) error {
+	if i >= 0 && i < r.Len() {
+		return nil
+	}
+	return r.recordError(i, ErrNoCell)
+}
+
+// recordError wraps err in a ParseError for cell i of the current record. The position is that of the start
+// of the record. It is used where cell i may not exist.
+func (r *Reader) recordError(i int, err error) error {
+	return &ParseError{
+		Line:      r.recordLine,
+		Col:       1,
+		StartLine: r.recordLine,
+		Record:    r.record,
+		Cell:      i,
+		Column:    r.columnName(i),
+		Offset:    r.recordOffset,
+		Err:       err,
+	}
+}
+
+func (r *Reader) columnName(i int) string {
+	if i >= 0 && i < len(r.header) {
+		return r.header[i]
+	}
+	return ""
+}
@@ -0,0 +1,78 @@
+package csv_test
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/philpearl/csv"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseErrorPosition(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		exp  csv.ParseError
+	}{
+		{
+			name: "char after quote",
+			in:   "a,b\nc,\"d\"x",
+			exp:  csv.ParseError{Line: 2, Col: 6, StartLine: 2, Record: 2, Cell: 1, Offset: 9},
+		},
+		{
+			name: "after quoted newlines",
+			in:   "a,\"b\nc\r\nd\"\n\"e\" f",
+			exp:  csv.ParseError{Line: 4, Col: 5, StartLine: 4, Record: 2, Cell: 0, Offset: 15},
+		},
+		{
+			name: "within multi-line record",
+			in:   "a,\"b\nc\"x",
+			exp:  csv.ParseError{Line: 2, Col: 3, StartLine: 1, Record: 1, Cell: 1, Offset: 7},
+		},
+		{
+			name: "EOF in quote",
+			in:   "a,b\nc,\"d\n",
+			exp:  csv.ParseError{Line: 3, Col: 1, StartLine: 2, Record: 2, Cell: 1, Offset: 9, Err: io.ErrUnexpectedEOF},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Reading a byte at a time checks we keep track of position across buffer refills
+			r := csv.NewReader(iotest.OneByteReader(strings.NewReader(test.in)))
+			var err error
+			for err == nil {
+				err = r.Scan()
+			}
+
+			var pe *csv.ParseError
+			if !assert.True(t, errors.As(err, &pe), "error is %v", err) {
+				return
+			}
+			if test.exp.Err != nil {
+				assert.Equal(t, test.exp.Err, pe.Err)
+			}
+			test.exp.Err = pe.Err
+			assert.Equal(t, test.exp, *pe)
+		})
+	}
+}
+
+func TestConversionError(t *testing.T) {
+	r := csv.NewReader(strings.NewReader("name,price\nhat,1.5\ncoat,\"12,5\"\n"), csv.UseHeader())
+	assert.NoError(t, r.Scan())
+	assert.NoError(t, r.Scan())
+
+	_, err := r.FloatByName("price")
+	assert.EqualError(t, err, `record 3, cell 1 "price" (line 3, offset 24): strconv.ParseFloat: parsing "12,5": invalid syntax`)
+	assert.True(t, errors.Is(err, strconv.ErrSyntax))
+
+	var pe *csv.ParseError
+	assert.True(t, errors.As(err, &pe))
+	assert.Equal(t, "price", pe.Column)
+	assert.Equal(t, 1, pe.Cell)
+}
@@ -0,0 +1,201 @@
+package csv
+
+import (
+	"encoding"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// field describes a struct field that maps to a CSV column. Fields are found by typeFields, which follows
+// embedded structs in the same way as encoding/json.
+type field struct {
+	name  string
+	index []int
+	typ   reflect.Type
+	// tagged is set if the name comes from a csv tag
+	tagged bool
+
+	// ptr is true if typ is a pointer. elem is typ with any pointer removed.
+	ptr  bool
+	elem reflect.Type
+	// unmarshaler is true if a pointer to elem implements encoding.TextUnmarshaler
+	unmarshaler bool
+	// marshaler is true if elem implements encoding.TextMarshaler. ptrMarshaler is true if only a pointer to
+	// elem does.
+	marshaler    bool
+	ptrMarshaler bool
+
+	// Options from the tag. omitEmpty writes an empty cell for zero values. format and prec control how
+	// floats are written, as for strconv.FormatFloat.
+	omitEmpty bool
+	format    byte
+	prec      int
+}
+
+var (
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+	textMarshalerType   = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// fieldCache holds the []field for each struct type we've seen, keyed by reflect.Type
+var fieldCache sync.Map
+
+// typeFields returns the CSV fields of struct type t. The result is cached.
+func typeFields(t reflect.Type) []field {
+	if f, ok := fieldCache.Load(t); ok {
+		return f.([]field)
+	}
+	f, _ := fieldCache.LoadOrStore(t, buildFields(t))
+	return f.([]field)
+}
+
+func buildFields(t reflect.Type) []field {
+	var fields []field
+	collectFields(t, nil, map[reflect.Type]bool{}, &fields)
+
+	byName := make(map[string][]int, len(fields))
+	for i, f := range fields {
+		byName[f.name] = append(byName[f.name], i)
+	}
+	var out []field
+	for i, f := range fields {
+		if dominantField(fields, byName[f.name]) == i {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// dominantField returns which of the fields with the given indices, which all have the same name, is used.
+// As in encoding/json the shallowest field is used. If there are several at that depth a field with a tag
+// is used, but only if it is the only one. Otherwise -1 is returned, and none of the fields are used.
+func dominantField(fields []field, indices []int) int {
+	depth := len(fields[indices[0]].index)
+	for _, i := range indices {
+		depth = min(depth, len(fields[i].index))
+	}
+	dominant, tagged, count := -1, 0, 0
+	for _, i := range indices {
+		f := &fields[i]
+		if len(f.index) != depth {
+			continue
+		}
+		count++
+		if f.tagged {
+			tagged++
+			dominant = i
+		} else if count == 1 {
+			dominant = i
+		}
+	}
+	if count == 1 || tagged == 1 {
+		return dominant
+	}
+	return -1
+}
+
+// collectFields appends the fields of struct type t to fields, following embedded structs. path holds the
+// struct types we're already inside, so a type that embeds itself isn't followed forever. Its fields are
+// shallower each time it's seen, so they'd never be used.
+func collectFields(t reflect.Type, index []int, path map[reflect.Type]bool, fields *[]field) {
+	path[t] = true
+	defer delete(path, t)
+
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		tag, hasTag := sf.Tag.Lookup("csv")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		fieldIndex := make([]int, len(index)+1)
+		copy(fieldIndex, index)
+		fieldIndex[len(index)] = i
+
+		if sf.Anonymous && (!hasTag || name == "") {
+			et := sf.Type
+			if et.Kind() == reflect.Pointer {
+				if !sf.IsExported() {
+					// We can't allocate a nil pointer to an unexported struct type
+					continue
+				}
+				et = et.Elem()
+			}
+			if et.Kind() == reflect.Struct && !reflect.PointerTo(et).Implements(textUnmarshalerType) {
+				if !path[et] {
+					collectFields(et, fieldIndex, path, fields)
+				}
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		tagged := name != ""
+		if !tagged {
+			name = sf.Name
+		}
+
+		f := field{
+			tagged: tagged,
+			name:   name,
+			index:  fieldIndex,
+			typ:    sf.Type,
+			elem:   sf.Type,
+			format: 'g',
+			prec:   -1,
+		}
+		if f.typ.Kind() == reflect.Pointer {
+			f.ptr = true
+			f.elem = f.typ.Elem()
+		}
+		f.unmarshaler = reflect.PointerTo(f.elem).Implements(textUnmarshalerType)
+		f.marshaler = f.elem.Implements(textMarshalerType)
+		f.ptrMarshaler = !f.marshaler && reflect.PointerTo(f.elem).Implements(textMarshalerType)
+		f.parseOptions(opts)
+		*fields = append(*fields, f)
+	}
+}
+
+// parseOptions parses the options following the name in a csv tag. These are a comma separated list
+// containing omitempty, format=<c> and prec=<n>. Unknown or malformed options are ignored.
+func (f *field) parseOptions(opts string) {
+	for opts != "" {
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		key, val, _ := strings.Cut(opt, "=")
+		switch key {
+		case "omitempty":
+			f.omitEmpty = true
+		case "format":
+			if len(val) == 1 {
+				f.format = val[0]
+			}
+		case "prec":
+			if prec, err := strconv.Atoi(val); err == nil {
+				f.prec = prec
+			}
+		}
+	}
+}
+
+// fieldByIndex returns the field of struct value v with the given index path. Nil embedded struct pointers
+// are allocated on the way if alloc is true, otherwise an invalid Value is returned if one is found.
+func fieldByIndex(v reflect.Value, index []int, alloc bool) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !alloc {
+					return reflect.Value{}
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
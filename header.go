@@ -0,0 +1,188 @@
+package csv
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+var (
+	// ErrMissingColumn is returned if a column is not present in the header.
+	ErrMissingColumn = errors.New("missing column")
+	// ErrDuplicateColumn is returned if a column name appears more than once in the header and UniqueHeader
+	// is set.
+	ErrDuplicateColumn = errors.New("duplicate column")
+)
+
+// UseHeader makes the Reader treat the first row of each input as a header. The header is consumed by the
+// first call to Scan (or Read or Bytes), which returns the first row after it. Cells can then be found by
+// column name using Index and the ByName accessors.
+func UseHeader() ReaderOption {
+	return readerOptionFunc(func(r *Reader) {
+		r.useHeader = true
+	})
+}
+
+// CaseInsensitiveHeader makes column names match the header without regard to case. It implies UseHeader.
+func CaseInsensitiveHeader() ReaderOption {
+	return readerOptionFunc(func(r *Reader) {
+		r.useHeader = true
+		r.headerFold = true
+	})
+}
+
+// UniqueHeader makes reading the header fail with ErrDuplicateColumn if a column name is repeated. Without
+// this option the first column with a given name is used. It implies UseHeader.
+func UniqueHeader() ReaderOption {
+	return readerOptionFunc(func(r *Reader) {
+		r.useHeader = true
+		r.headerUnique = true
+	})
+}
+
+// RequireColumns makes reading the header fail with ErrMissingColumn if any of the named columns are not
+// present. It implies UseHeader.
+func RequireColumns(names ...string) ReaderOption {
+	return readerOptionFunc(func(r *Reader) {
+		r.useHeader = true
+		r.required = append(r.required, names...)
+	})
+}
+
+// Header returns the column names from the header row, reading it if necessary. It returns nil if the
+// Reader is not using a header. The returned slice must not be modified.
+func (r *Reader) Header() ([]string, error) {
+	if err := r.checkHeader(); err != nil {
+		return nil, err
+	}
+	return r.header, nil
+}
+
+// Index returns the index of the cell with the given column name. It returns false if there is no such
+// column. Index is only valid once the header has been read.
+func (r *Reader) Index(name string) (int, bool) {
+	if r.headerFold {
+		name = foldName(name)
+	}
+	i, ok := r.headerIndex[name]
+	return i, ok
+}
+
+// foldName returns the key for name in headerIndex when CaseInsensitiveHeader is used. Names that
+// strings.EqualFold considers equal have the same key. Each rune is replaced by the lower case of the
+// smallest rune in its case folding orbit, so ASCII names that are already lower case are unchanged and
+// aren't copied.
+func foldName(name string) string {
+	return strings.Map(func(c rune) rune {
+		smallest := c
+		for f := unicode.SimpleFold(c); f != c; f = unicode.SimpleFold(f) {
+			smallest = min(smallest, f)
+		}
+		return unicode.ToLower(smallest)
+	}, name)
+}
+
+// IntByName reads the cell in the named column of the current row as an int.
+func (r *Reader) IntByName(name string) (int, error) {
+	i, err := r.index(name)
+	if err != nil {
+		return 0, err
+	}
+	return r.Int(i)
+}
+
+// FloatByName reads the cell in the named column of the current row as a float.
+func (r *Reader) FloatByName(name string) (float64, error) {
+	i, err := r.index(name)
+	if err != nil {
+		return 0, err
+	}
+	return r.Float(i)
+}
+
+// BoolByName reads the cell in the named column of the current row as a boolean value.
+func (r *Reader) BoolByName(name string) (bool, error) {
+	i, err := r.index(name)
+	if err != nil {
+		return false, err
+	}
+	return r.Bool(i)
+}
+
+// TextByName reads the cell in the named column of the current row as a string.
+func (r *Reader) TextByName(name string) (string, error) {
+	i, err := r.index(name)
+	if err != nil {
+		return "", err
+	}
+	return r.Text(i), nil
+}
+
+// RawByName returns the raw parsed bytes of the cell in the named column of the current row. The contents
+// are only valid until the next call to Read, Scan or Bytes.
+func (r *Reader) RawByName(name string) ([]byte, error) {
+	i, err := r.index(name)
+	if err != nil {
+		return nil, err
+	}
+	return r.Raw(i), nil
+}
+
+// index is Index for the ByName accessors. It also checks the column exists in the current row.
+func (r *Reader) index(name string) (int, error) {
+	i, ok := r.Index(name)
+	if !ok || i >= r.Len() {
+		return 0, fmt.Errorf("%w %q", ErrMissingColumn, name)
+	}
+	return i, nil
+}
+
+// checkHeader reads the header if it hasn't been read yet. If the header is invalid the same error is
+// returned every time.
+func (r *Reader) checkHeader() error {
+	if r.headerErr != nil {
+		return r.headerErr
+	}
+	if r.useHeader && r.header == nil {
+		return r.readHeader()
+	}
+	return nil
+}
+
+func (r *Reader) readHeader() error {
+	// A header with the wrong number of cells is still used, so we don't read it again
+	countErr := r.scanRow()
+	if countErr != nil && !errors.Is(countErr, ErrFieldCount) {
+		return countErr
+	}
+	r.header = append(r.header[:0], r.rowStrings()...)
+	r.headerCount++
+
+	clear(r.headerIndex)
+	if r.headerIndex == nil {
+		r.headerIndex = make(map[string]int, len(r.header))
+	}
+	for i, name := range r.header {
+		key := name
+		if r.headerFold {
+			key = foldName(name)
+		}
+		if _, ok := r.headerIndex[key]; ok {
+			if r.headerUnique {
+				r.headerErr = fmt.Errorf("%w %q in header", ErrDuplicateColumn, name)
+				return r.headerErr
+			}
+			continue
+		}
+		r.headerIndex[key] = i
+	}
+
+	for _, name := range r.required {
+		if _, ok := r.Index(name); !ok {
+			r.headerErr = fmt.Errorf("%w %q in header", ErrMissingColumn, name)
+			return r.headerErr
+		}
+	}
+	return countErr
+}
@@ -0,0 +1,205 @@
+package csv_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/philpearl/csv"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeader(t *testing.T) {
+	r := csv.NewReader(strings.NewReader("name, count, price\nhat, 3, 1.5\ncoat, 1, 99\n"), csv.UseHeader())
+
+	header, err := r.Header()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"name", "count", "price"}, header)
+
+	assert.NoError(t, r.Scan())
+	i, ok := r.Index("price")
+	assert.True(t, ok)
+	assert.Equal(t, 2, i)
+	_, ok = r.Index("Price")
+	assert.False(t, ok)
+
+	name, err := r.TextByName("name")
+	assert.NoError(t, err)
+	assert.Equal(t, "hat", name)
+
+	count, err := r.IntByName("count")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	price, err := r.FloatByName("price")
+	assert.NoError(t, err)
+	assert.Equal(t, 1.5, price)
+
+	raw, err := r.RawByName("name")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("hat"), raw)
+
+	_, err = r.BoolByName("missing")
+	assert.EqualError(t, err, `missing column "missing"`)
+	assert.True(t, errors.Is(err, csv.ErrMissingColumn))
+
+	assert.NoError(t, r.Scan())
+	name, err = r.TextByName("name")
+	assert.NoError(t, err)
+	assert.Equal(t, "coat", name)
+
+	// The new input has a new header
+	r.SetInput(strings.NewReader("price,name\n2,scarf"))
+	assert.NoError(t, r.Scan())
+	name, err = r.TextByName("name")
+	assert.NoError(t, err)
+	assert.Equal(t, "scarf", name)
+
+	assert.Equal(t, io.EOF, r.Scan())
+}
+
+func TestHeaderOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		opts []csv.ReaderOption
+		err  string
+		col  string
+		exp  int
+	}{
+		{
+			name: "case insensitive",
+			in:   "Name,COUNT\nhat,3",
+			opts: []csv.ReaderOption{csv.CaseInsensitiveHeader()},
+			col:  "count",
+			exp:  3,
+		},
+		{
+			name: "duplicates allowed",
+			in:   "count,count\n1,2",
+			opts: []csv.ReaderOption{csv.UseHeader()},
+			col:  "count",
+			exp:  1,
+		},
+		{
+			name: "duplicates",
+			in:   "count,count\n1,2",
+			opts: []csv.ReaderOption{csv.UniqueHeader()},
+			err:  `duplicate column "count" in header`,
+		},
+		{
+			name: "duplicates case insensitive",
+			in:   "count,Count\n1,2",
+			opts: []csv.ReaderOption{csv.UniqueHeader(), csv.CaseInsensitiveHeader()},
+			err:  `duplicate column "Count" in header`,
+		},
+		{
+			name: "duplicates case folding",
+			in:   "class,claſs\n1,2",
+			opts: []csv.ReaderOption{csv.UniqueHeader(), csv.CaseInsensitiveHeader()},
+			err:  `duplicate column "claſs" in header`,
+		},
+		{
+			name: "case folding",
+			in:   "Name,\u212aount\nhat,3",
+			opts: []csv.ReaderOption{csv.CaseInsensitiveHeader()},
+			col:  "KOUNT",
+			exp:  3,
+		},
+		{
+			name: "required",
+			in:   "name,count\nhat,3",
+			opts: []csv.ReaderOption{csv.RequireColumns("name", "count")},
+			col:  "count",
+			exp:  3,
+		},
+		{
+			name: "required missing",
+			in:   "name,count\nhat,3",
+			opts: []csv.ReaderOption{csv.RequireColumns("name", "price")},
+			err:  `missing column "price" in header`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := csv.NewReader(strings.NewReader(test.in), test.opts...)
+			err := r.Scan()
+			if test.err != "" {
+				assert.EqualError(t, err, test.err)
+				// The error sticks, rather than the first row being returned
+				assert.EqualError(t, r.Scan(), test.err)
+				_, err = r.Header()
+				assert.EqualError(t, err, test.err)
+				return
+			}
+			assert.NoError(t, err)
+			v, err := r.IntByName(test.col)
+			assert.NoError(t, err)
+			assert.Equal(t, test.exp, v)
+		})
+	}
+}
+
+func TestHeaderShortRow(t *testing.T) {
+	r := csv.NewReader(strings.NewReader("a,b\n1\n"), csv.UseHeader())
+	assert.NoError(t, r.Scan())
+	_, err := r.IntByName("b")
+	assert.EqualError(t, err, `missing column "b"`)
+}
+
+func TestNoHeader(t *testing.T) {
+	r := csv.NewReader(strings.NewReader("a,b\n1,2\n"))
+	header, err := r.Header()
+	assert.NoError(t, err)
+	assert.Nil(t, header)
+	_, ok := r.Index("a")
+	assert.False(t, ok)
+}
+
+func TestDecodeCaseInsensitive(t *testing.T) {
+	type row struct {
+		Name  string `csv:"name"`
+		Count int    `csv:"count"`
+	}
+	d := csv.NewDecoder(csv.NewReader(strings.NewReader("NAME,Count\nhat,3\n"), csv.CaseInsensitiveHeader()))
+	var v row
+	assert.NoError(t, d.Decode(&v))
+	assert.Equal(t, row{Name: "hat", Count: 3}, v)
+	assert.Equal(t, io.EOF, d.Decode(&v))
+}
+
+func TestDecodeHeaderError(t *testing.T) {
+	type row struct {
+		Name string `csv:"name"`
+	}
+	d := csv.NewDecoder(csv.NewReader(strings.NewReader("name,name\nhat,coat\n"), csv.UniqueHeader()))
+	var v row
+	assert.EqualError(t, d.Decode(&v), `duplicate column "name" in header`)
+	assert.EqualError(t, d.Decode(&v), `duplicate column "name" in header`)
+	assert.Equal(t, row{}, v)
+}
+
+func BenchmarkFloatByName(b *testing.B) {
+	content := []byte(`cheese, feet, lemon, 99, 1002, 1298, 12.3, 17, 11, whale
+`)
+	r := csv.NewReader(&repeatReader{content: content}, csv.UseHeader())
+
+	b.SetBytes(int64(len(content)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	total := 0.0
+	for i := 0; i < b.N; i++ {
+		if err := r.Scan(); err != nil {
+			b.Fatal(err)
+		}
+		f, err := r.FloatByName("12.3")
+		if err != nil {
+			b.Fatal(err)
+		}
+		total += f
+	}
+	assert.InEpsilon(b, float64(b.N)*12.3, total, 0.1)
+}
@@ -0,0 +1,91 @@
+package csv_test
+
+import (
+	"io"
+
+	"github.com/philpearl/csv"
+)
+
+// rowDetail records everything about a row that two ways of reading it should agree about
+type rowDetail struct {
+	Cells  []string
+	Quoted []bool
+	Pos    [][2]int
+	Offset []int64
+	Start  int64
+	End    int64
+	// IntErr checks the record number and column name in errors
+	IntErr string
+}
+
+func readRowDetail(r *csv.Reader) rowDetail {
+	row := rowDetail{
+		Start: r.RecordOffset(),
+		End:   r.InputOffset(),
+	}
+	for i := range r.Len() {
+		line, col := r.FieldPos(i)
+		row.Cells = append(row.Cells, r.Text(i))
+		row.Quoted = append(row.Quoted, r.IsQuoted(i))
+		row.Pos = append(row.Pos, [2]int{line, col})
+		row.Offset = append(row.Offset, r.CellOffset(i))
+	}
+	if _, err := r.Int(r.Len() - 1); err != nil {
+		row.IntErr = err.Error()
+	}
+	return row
+}
+
+// readSequential reads in with a Reader, returning the rows and the error that stopped it
+func readSequential(in io.Reader, opts ...csv.ReaderOption) ([]rowDetail, string) {
+	r := csv.NewReader(in, opts...)
+	var rows []rowDetail
+	for row, err := range r.Rows() {
+		if err != nil {
+			return rows, err.Error()
+		}
+		rows = append(rows, readRowDetail(row))
+	}
+	return rows, ""
+}
+
+// testInputs and testConfigs are used by the tests that check different ways of reading agree
+var testInputs = []string{
+	"",
+	"\n",
+	"a",
+	"a,b\n",
+	"a,b\nc,d\ne,f",
+	"a,b\r\nc,d\r\n\r\ne,f\r\n",
+	"\ufeffa,b\nc,d\n",
+	"a,\"b\nc\",d\n\"e\n\nf\",g\n1,2\n",
+	"a,\"b\"\"\nc\"\n\"\"\"\",\"\n\"\n1,2\n",
+	"a\"b,c\nd,e\"f\n\"g\nh\",i\n",
+	"a,b\n\n\n\nc,d\n\n",
+	"#x,\"y\n#z\na,b\n#\"\n#\nc,\"d\n#e\"\n",
+	"a,1\nb,2\n\"c\nd\"x,3\ne,4\n",
+	"a,1\nb,2\nc,\"3\nd,4\n",
+	"a,b,c\nd,e\nf,g,h\n",
+	"name,n\nhat,1\ncoat,2\n\"big\nboots\",3\nscarf,4\n",
+	"a\t\tb\n\t\"c\"\t \"d\"\n",
+	"a  b\n \"c\"  \td\n\t \n",
+	"a;'b;c'\n'd''e\nf';g\n;\n",
+}
+
+var testConfigs = []struct {
+	name string
+	opts []csv.ReaderOption
+}{
+	{name: "default"},
+	{name: "header", opts: []csv.ReaderOption{csv.UseHeader()}},
+	{name: "strict", opts: []csv.ReaderOption{csv.Strict()}},
+	{name: "lazy", opts: []csv.ReaderOption{csv.LazyQuotes()}},
+	{name: "stdlib", opts: []csv.ReaderOption{csv.StdlibCompatible()}},
+	{name: "comment", opts: []csv.ReaderOption{csv.Comment('#'), csv.SkipBlankLines()}},
+	{name: "fields", opts: []csv.ReaderOption{csv.FieldsPerRecord(0)}},
+	{name: "header fields", opts: []csv.ReaderOption{csv.UseHeader(), csv.FieldsPerRecord(0)}},
+	{name: "tab", opts: []csv.ReaderOption{csv.Dialect{Comma: '\t'}}},
+	{name: "space", opts: []csv.ReaderOption{csv.Dialect{Comma: ' '}}},
+	{name: "semicolon", opts: []csv.ReaderOption{csv.Dialect{Comma: ';', Quote: '\''}}},
+	{name: "tab header fields", opts: []csv.ReaderOption{csv.Dialect{Comma: '\t'}, csv.UseHeader(), csv.FieldsPerRecord(0)}},
+}
@@ -0,0 +1,221 @@
+package csv
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrInvalidIndex means the data passed to RowIndex.ReadFrom is not an index written by RowIndex.WriteTo.
+var ErrInvalidIndex = errors.New("invalid CSV index")
+
+// indexMagic starts an index written by WriteTo. The last byte is the version of the format
+var indexMagic = []byte("csvindex\x01")
+
+// RowIndex records where rows start in a CSV file, so that a SeekReader can find any row without reading
+// the whole file. It records the position of every Kth row. Rows count from 0 and don't include the header.
+// Create a RowIndex with BuildRowIndex, and save it with WriteTo so it can be loaded again with ReadFrom.
+type RowIndex struct {
+	every int
+	rows  int
+	// entries are the positions before each indexed row
+	entries []Checkpoint
+}
+
+// BuildRowIndex reads the remaining input of r, which must not yet have been read, and returns a RowIndex
+// that records the position of every row that is a multiple of every. If every is less than 1 every row is
+// indexed. Rows that OnBadRecord skips are not counted, and nor is the empty record Scan returns after a line
+// ending at the end of the input. BuildRowIndex returns any error Scan returns other than io.EOF. A RowIndex
+// is only valid for use with a Reader that has the same options as r, and with the same input.
+func BuildRowIndex(r *Reader, every int) (*RowIndex, error) {
+	ix := &RowIndex{every: max(every, 1)}
+	if err := r.checkHeader(); err != nil {
+		return nil, err
+	}
+	for {
+		cp := r.Checkpoint()
+		if err := r.Scan(); err != nil {
+			if err == io.EOF {
+				return ix, nil
+			}
+			return nil, err
+		}
+		if r.RecordOffset() == r.InputOffset() {
+			// The record has no input, so is the empty record after a final line ending. It isn't a row
+			// anyone wants to see, and Scan returns io.EOF next
+			continue
+		}
+		if ix.rows%ix.every == 0 {
+			ix.entries = append(ix.entries, cp)
+		}
+		ix.rows++
+	}
+}
+
+// Len returns the number of rows in the indexed file. It doesn't count the empty record that Scan returns
+// after a line ending at the end of the input.
+func (ix *RowIndex) Len() int {
+	return ix.rows
+}
+
+// find returns the indexed row at or before row n, and the position before it. n must be less than Len.
+func (ix *RowIndex) find(n int) (row int, cp Checkpoint) {
+	i := n / ix.every
+	return i * ix.every, ix.entries[i]
+}
+
+// WriteTo writes the index to w in a compact binary format. It implements io.WriterTo.
+func (ix *RowIndex) WriteTo(w io.Writer) (int64, error) {
+	b := make([]byte, 0, len(indexMagic)+3*binary.MaxVarintLen64+len(ix.entries)*8)
+	b = append(b, indexMagic...)
+	b = binary.AppendUvarint(b, uint64(ix.every))
+	b = binary.AppendUvarint(b, uint64(ix.rows))
+	b = binary.AppendUvarint(b, uint64(len(ix.entries)))
+	// Each entry is stored as the difference from the last, as these are small
+	var last Checkpoint
+	for _, cp := range ix.entries {
+		b = binary.AppendUvarint(b, uint64(cp.Offset-last.Offset))
+		b = binary.AppendUvarint(b, uint64(cp.Line-last.Line))
+		b = binary.AppendUvarint(b, uint64(cp.Record-last.Record))
+		last = cp
+	}
+	n, err := w.Write(b)
+	return int64(n), err
+}
+
+// ReadFrom reads an index written by WriteTo from r, replacing the contents of ix. It reads r until EOF.
+// It returns an error wrapping ErrInvalidIndex if the data is not a valid index. It implements
+// io.ReaderFrom.
+func (ix *RowIndex) ReadFrom(r io.Reader) (int64, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return int64(len(data)), err
+	}
+	if err := ix.unmarshal(data); err != nil {
+		return int64(len(data)), fmt.Errorf("%w: %w", ErrInvalidIndex, err)
+	}
+	return int64(len(data)), nil
+}
+
+func (ix *RowIndex) unmarshal(data []byte) error {
+	b, ok := bytes.CutPrefix(data, indexMagic)
+	if !ok {
+		return errors.New("unrecognised header")
+	}
+	next := func() int64 {
+		v, n := binary.Uvarint(b)
+		if n <= 0 || v > 1<<62 {
+			b, ok = nil, false
+			return 0
+		}
+		b = b[n:]
+		return int64(v)
+	}
+
+	every, rows, count := int(next()), int(next()), int(next())
+	switch {
+	case !ok:
+		return io.ErrUnexpectedEOF
+	case every < 1:
+		return fmt.Errorf("interval %d is less than 1", every)
+	case count != (rows+every-1)/every:
+		return fmt.Errorf("%d entries for %d rows at interval %d", count, rows, every)
+	}
+
+	entries := make([]Checkpoint, 0, min(count, len(b)))
+	var cp Checkpoint
+	for range count {
+		cp.Offset += next()
+		cp.Line += int(next())
+		cp.Record += int(next())
+		if !ok {
+			return io.ErrUnexpectedEOF
+		}
+		entries = append(entries, cp)
+	}
+	if len(b) != 0 {
+		return errors.New("unexpected data after index")
+	}
+	*ix = RowIndex{every: every, rows: rows, entries: entries}
+	return nil
+}
+
+// SeekReader is a Reader over an io.ReaderAt that uses a RowIndex to move quickly to any row. Use SeekRow
+// to move to a row, then Scan or Read to read rows from there.
+type SeekReader struct {
+	*Reader
+	in    io.ReaderAt
+	size  int64
+	index *RowIndex
+
+	// inited is set once we've read the header and learned the number of cells per record. These are kept
+	// each time we move
+	inited bool
+	err    error
+}
+
+// NewSeekReader creates a SeekReader that reads size bytes from in. index must have been built from the same
+// input using a Reader with the same options. Until SeekRow is called the SeekReader reads from the start of
+// the input. InputEncoding can't be used, and NewSeekReader panics if it is.
+func NewSeekReader(in io.ReaderAt, size int64, index *RowIndex, opts ...ReaderOption) *SeekReader {
+	r := NewReader(io.NewSectionReader(in, 0, size), opts...)
+	r.checkSeekable("SeekReader")
+	return &SeekReader{
+		Reader: r,
+		in:     in,
+		size:   size,
+		index:  index,
+	}
+}
+
+// SeekRow moves to row n, counting from 0 and not including the header, so the next call to Scan or Read
+// reads it. Rows from the indexed row before n are read to get there. If n is beyond the last row SeekRow
+// returns io.EOF, as does the next call to Scan.
+func (s *SeekReader) SeekRow(n int) error {
+	if n < 0 {
+		return fmt.Errorf("invalid row %d", n)
+	}
+	if err := s.init(); err != nil {
+		return err
+	}
+
+	if n >= s.index.Len() {
+		s.move(Checkpoint{Offset: s.size, EOF: true})
+		return io.EOF
+	}
+
+	row, cp := s.index.find(n)
+	s.move(cp)
+	for ; row < n; row++ {
+		if err := s.Scan(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// move sets the Reader up to read from cp
+func (s *SeekReader) move(cp Checkpoint) {
+	s.setInputAt(io.NewSectionReader(s.in, cp.Offset, s.size-cp.Offset), cp)
+}
+
+// init reads the header from the start of the input. If every record must have the same number of cells as
+// the first, it also finds out how many cells the first record has.
+func (s *SeekReader) init() error {
+	if s.inited {
+		return s.err
+	}
+	s.inited = true
+
+	r := s.Reader
+	r.SetInput(io.NewSectionReader(s.in, 0, s.size))
+	if r.useHeader {
+		if s.err = r.readHeader(); s.err != nil {
+			return s.err
+		}
+	}
+	r.readFieldCount()
+	return nil
+}
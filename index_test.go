@@ -0,0 +1,297 @@
+package csv_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/philpearl/csv"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeekReader(t *testing.T) {
+	for _, config := range testConfigs {
+		for i, in := range testInputs {
+			expRows, expErr := readSequential(strings.NewReader(in), config.opts...)
+			if expErr != "" {
+				continue
+			}
+			// The index doesn't count the empty record after a final line ending, but Scan still returns it
+			expLen := len(expRows)
+			if len(expRows) > 0 && expRows[len(expRows)-1].Start == expRows[len(expRows)-1].End {
+				expLen--
+			}
+			for _, every := range []int{1, 2, 3, 100} {
+				t.Run(fmt.Sprintf("%s/%d/%d", config.name, i, every), func(t *testing.T) {
+					index, err := csv.BuildRowIndex(csv.NewReader(strings.NewReader(in), config.opts...), every)
+					if !assert.NoError(t, err) {
+						return
+					}
+					assert.Equal(t, expLen, index.Len())
+
+					// Write the index out and read it back in, as it would be from a sidecar file
+					var buf bytes.Buffer
+					written, err := index.WriteTo(&buf)
+					assert.NoError(t, err)
+					data := bytes.Clone(buf.Bytes())
+					var loaded csv.RowIndex
+					read, err := loaded.ReadFrom(&buf)
+					assert.NoError(t, err)
+					assert.Equal(t, written, read)
+					assert.Equal(t, index.Len(), loaded.Len())
+					_, err = loaded.WriteTo(&buf)
+					assert.NoError(t, err)
+					assert.Equal(t, data, buf.Bytes())
+
+					s := csv.NewSeekReader(strings.NewReader(in), int64(len(in)), &loaded, config.opts...)
+					// Seek backwards so we're not just reading in order
+					for n := expLen - 1; n >= 0; n-- {
+						if !assert.NoError(t, s.SeekRow(n)) {
+							return
+						}
+						var rows []rowDetail
+						for row, err := range s.Rows() {
+							if !assert.NoError(t, err) {
+								return
+							}
+							rows = append(rows, readRowDetail(row))
+						}
+						assert.Equal(t, expRows[n:], rows)
+					}
+
+					assert.Equal(t, io.EOF, s.SeekRow(expLen))
+					assert.Equal(t, io.EOF, s.Scan())
+				})
+			}
+		}
+	}
+}
+
+func TestSeekRow(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    string
+		opts  []csv.ReaderOption
+		every int
+		n     int
+		exp   []string
+		err   string
+	}{
+		{
+			name:  "indexed",
+			in:    "a\nb\nc\nd\n",
+			every: 2,
+			n:     2,
+			exp:   []string{"c", "d", ""},
+		},
+		{
+			name:  "final line ending",
+			in:    "a\nb\n",
+			every: 1,
+			n:     2,
+			err:   "EOF",
+		},
+		{
+			name:  "between",
+			in:    "a\nb\nc\nd\n",
+			every: 2,
+			n:     3,
+			exp:   []string{"d", ""},
+		},
+		{
+			name:  "header",
+			in:    "h\na\nb\n",
+			opts:  []csv.ReaderOption{csv.UseHeader(), csv.SkipBlankLines()},
+			every: 1,
+			n:     0,
+			exp:   []string{"a", "b"},
+		},
+		{
+			name:  "skipped lines",
+			in:    "a\n#x\n\nb\n\n#y\nc\n",
+			opts:  []csv.ReaderOption{csv.Comment('#'), csv.SkipBlankLines()},
+			every: 1,
+			n:     2,
+			exp:   []string{"c"},
+		},
+		{
+			name:  "quoted",
+			in:    "\"a\nb\"\n\"c\n\"\nd\n",
+			opts:  []csv.ReaderOption{csv.SkipBlankLines()},
+			every: 1,
+			n:     1,
+			exp:   []string{"c\n", "d"},
+		},
+		{
+			name:  "field count",
+			in:    "a,1\nb,2\nc\n",
+			opts:  []csv.ReaderOption{csv.FieldsPerRecord(0), csv.SkipBlankLines()},
+			every: 1,
+			n:     2,
+			err:   "record 3, cell 1 (line 3, offset 8): wrong number of fields: have 1, want 2",
+		},
+		{
+			name:  "past end",
+			in:    "a\nb\n",
+			opts:  []csv.ReaderOption{csv.SkipBlankLines()},
+			every: 1,
+			n:     2,
+			err:   "EOF",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// The index can't be built from input that has errors, so we build it with a different count check
+			opts := append(slices.Clone(test.opts), csv.FieldsPerRecord(-1))
+			index, err := csv.BuildRowIndex(csv.NewReader(strings.NewReader(test.in), opts...), test.every)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			s := csv.NewSeekReader(strings.NewReader(test.in), int64(len(test.in)), index, test.opts...)
+			if err := s.SeekRow(test.n); err != nil {
+				assert.EqualError(t, err, test.err)
+				return
+			}
+			var cells []string
+			for row, err := range s.Rows() {
+				if err != nil {
+					assert.EqualError(t, err, test.err)
+					return
+				}
+				cells = append(cells, row.Text(0))
+			}
+			assert.Equal(t, test.exp, cells)
+		})
+	}
+}
+
+func TestSeekReaderInputEncoding(t *testing.T) {
+	assert.Panics(t, func() {
+		csv.NewSeekReader(strings.NewReader(""), 0, &csv.RowIndex{}, csv.InputEncoding(csv.ISO8859_1))
+	})
+}
+
+func TestSeekReaderLarge(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("id,text\n")
+	for i := range 10000 {
+		fmt.Fprintf(&b, "%d,\"line %d\nof %d\"\n", i, i, i)
+	}
+	in := b.String()
+
+	index, err := csv.BuildRowIndex(csv.NewReader(strings.NewReader(in), csv.UseHeader(), csv.SkipBlankLines()), 100)
+	assert.NoError(t, err)
+	assert.Equal(t, 10000, index.Len())
+
+	s := csv.NewSeekReader(strings.NewReader(in), int64(len(in)), index, csv.UseHeader(), csv.SkipBlankLines())
+	for _, n := range []int{9999, 0, 5050, 100, 99, 4321} {
+		assert.NoError(t, s.SeekRow(n))
+		assert.NoError(t, s.Scan())
+		id, err := s.IntByName("id")
+		assert.NoError(t, err)
+		assert.Equal(t, n, id)
+		text, err := s.TextByName("text")
+		assert.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("line %d\nof %d", n, n), text)
+		line, _ := s.FieldPos(0)
+		assert.Equal(t, 2*n+2, line)
+	}
+
+	// Errors report the right record
+	assert.NoError(t, s.SeekRow(777))
+	assert.NoError(t, s.Scan())
+	_, err = s.IntByName("text")
+	offset := strings.Index(in, "\n777,") + 5
+	assert.EqualError(t, err, fmt.Sprintf(`record 779, cell 1 "text" (line 1556, offset %d): strconv.Atoi: parsing "line 777\nof 777": invalid syntax`, offset))
+}
+
+func TestSeekReaderErrors(t *testing.T) {
+	index, err := csv.BuildRowIndex(csv.NewReader(strings.NewReader("a\nb\n")), 1)
+	assert.NoError(t, err)
+
+	s := csv.NewSeekReader(strings.NewReader("a\nb\n"), 4, index)
+	assert.EqualError(t, s.SeekRow(-1), "invalid row -1")
+
+	// The header is read before we seek
+	in := "a,a\n1,2\n"
+	s = csv.NewSeekReader(strings.NewReader(in), int64(len(in)), index, csv.UniqueHeader())
+	assert.True(t, errors.Is(s.SeekRow(1), csv.ErrDuplicateColumn))
+
+	// An error building the index
+	_, err = csv.BuildRowIndex(csv.NewReader(strings.NewReader("a\n\"b\"c\n"), csv.Strict()), 1)
+	assert.EqualError(t, err, "record 2, cell 0 (line 2, offset 5): extraneous or missing quote in quoted cell")
+}
+
+func TestRowIndexReadFromInvalid(t *testing.T) {
+	index, err := csv.BuildRowIndex(csv.NewReader(strings.NewReader("a\nb\nc\nd\ne\n")), 2)
+	assert.NoError(t, err)
+	var buf bytes.Buffer
+	_, err = index.WriteTo(&buf)
+	assert.NoError(t, err)
+	data := buf.Bytes()
+
+	tests := []struct {
+		name string
+		data []byte
+		err  string
+	}{
+		{name: "empty", err: "invalid CSV index: unrecognised header"},
+		{name: "not index", data: []byte("a,b,c\n"), err: "invalid CSV index: unrecognised header"},
+		{name: "truncated", data: data[:len(data)-1], err: "invalid CSV index: unexpected EOF"},
+		{name: "trailing", data: append(bytes.Clone(data), 0), err: "invalid CSV index: unexpected data after index"},
+		{name: "interval", data: []byte("csvindex\x01\x00\x00\x00"), err: "invalid CSV index: interval 0 is less than 1"},
+		{name: "count", data: []byte("csvindex\x01\x01\x02\x01\x00\x00\x00"), err: "invalid CSV index: 1 entries for 2 rows at interval 1"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var ix csv.RowIndex
+			_, err := ix.ReadFrom(bytes.NewReader(test.data))
+			assert.True(t, errors.Is(err, csv.ErrInvalidIndex))
+			assert.EqualError(t, err, test.err)
+		})
+	}
+}
+
+func ExampleSeekReader() {
+	in := strings.NewReader("name,age\nAlice,34\nBob,27\nCarol,51\nDave,19\n")
+
+	// Build the index once, and keep it with the file
+	index, err := csv.BuildRowIndex(csv.NewReader(in, csv.UseHeader(), csv.SkipBlankLines()), 2)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	var sidecar bytes.Buffer
+	if _, err := index.WriteTo(&sidecar); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	// Later, load the index and jump straight to a row
+	var loaded csv.RowIndex
+	if _, err := loaded.ReadFrom(&sidecar); err != nil {
+		fmt.Println(err)
+		return
+	}
+	s := csv.NewSeekReader(in, in.Size(), &loaded, csv.UseHeader(), csv.SkipBlankLines())
+	if err := s.SeekRow(2); err != nil {
+		fmt.Println(err)
+		return
+	}
+	for row, err := range s.Rows() {
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Println(row.Text(0))
+	}
+
+	// Output: Carol
+	// Dave
+}
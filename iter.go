@@ -0,0 +1,73 @@
+package csv
+
+import (
+	"io"
+	"iter"
+)
+
+// Rows returns an iterator over the remaining rows of the CSV. Each iteration scans a row and yields the Reader
+// so cells can be read with Int, Text, etc. Iteration stops at the end of the input. If an error other than
+// io.EOF occurs it is yielded with a nil Reader and iteration stops.
+//
+//	for row, err := range r.Rows() {
+//		if err != nil {
+//			return err
+//		}
+//		fmt.Println(row.Text(0))
+//	}
+func (r *Reader) Rows() iter.Seq2[*Reader, error] {
+	return func(yield func(*Reader, error) bool) {
+		for {
+			if err := r.Scan(); err != nil {
+				if err != io.EOF {
+					yield(nil, err)
+				}
+				return
+			}
+			if !yield(r, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Records returns an iterator over the remaining rows of the CSV as []string, as returned by Read. The slice
+// is only valid until the next iteration, but the strings remain valid. Errors are handled as for Rows.
+func (r *Reader) Records() iter.Seq2[[]string, error] {
+	return func(yield func([]string, error) bool) {
+		for {
+			record, err := r.Read()
+			if err != nil {
+				if err != io.EOF {
+					yield(nil, err)
+				}
+				return
+			}
+			if !yield(record, nil) {
+				return
+			}
+		}
+	}
+}
+
+// DecodeAll returns an iterator that decodes the remaining rows of d into values of struct type T. Each row
+// is decoded into a new T, so values may be retained after the iteration that yields them. Iteration stops
+// at the end of the input. If Decode returns any other error it is yielded with the zero value of T and
+// iteration stops.
+func DecodeAll[T any](d *Decoder) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for {
+			var v T
+			if err := d.Decode(&v); err != nil {
+				if err != io.EOF {
+					var zero T
+					yield(zero, err)
+				}
+				return
+			}
+			if !yield(v, nil) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package csv_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/philpearl/csv"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRows(t *testing.T) {
+	r := csv.NewReader(strings.NewReader("a,1\nb,2\nc,3"))
+
+	var names []string
+	var total int
+	for row, err := range r.Rows() {
+		if !assert.NoError(t, err) {
+			return
+		}
+		names = append(names, row.Text(0))
+		v, err := row.Int(1)
+		assert.NoError(t, err)
+		total += v
+	}
+	assert.Equal(t, []string{"a", "b", "c"}, names)
+	assert.Equal(t, 6, total)
+}
+
+func TestRowsBreak(t *testing.T) {
+	r := csv.NewReader(strings.NewReader("a\nb\nc"))
+	for row := range r.Rows() {
+		if row.Text(0) == "a" {
+			break
+		}
+	}
+	// Breaking leaves the remaining rows to be read
+	var rest []string
+	for row := range r.Rows() {
+		rest = append(rest, row.Text(0))
+	}
+	assert.Equal(t, []string{"b", "c"}, rest)
+}
+
+func TestRowsError(t *testing.T) {
+	r := csv.NewReader(strings.NewReader("a\n\"b\"c\nd\n"), csv.Strict())
+
+	var names []string
+	var errs []error
+	for row, err := range r.Rows() {
+		if err != nil {
+			assert.Nil(t, row)
+			errs = append(errs, err)
+			continue
+		}
+		names = append(names, row.Text(0))
+	}
+	assert.Equal(t, []string{"a"}, names)
+	if assert.Len(t, errs, 1) {
+		assert.EqualError(t, errs[0], `record 2, cell 0 (line 2, offset 5): extraneous or missing quote in quoted cell`)
+	}
+}
+
+func TestRecords(t *testing.T) {
+	r := csv.NewReader(strings.NewReader("a,b\nc,d\n"), csv.SkipBlankLines())
+
+	var records [][]string
+	for record, err := range r.Records() {
+		if !assert.NoError(t, err) {
+			return
+		}
+		records = append(records, append([]string(nil), record...))
+	}
+	assert.Equal(t, [][]string{{"a", "b"}, {"c", "d"}}, records)
+}
+
+func TestRecordsBreak(t *testing.T) {
+	r := csv.NewReader(strings.NewReader("a\nb\nc\n"))
+	var count int
+	for range r.Records() {
+		count++
+		if count == 2 {
+			break
+		}
+	}
+	assert.Equal(t, 2, count)
+}
+
+func TestDecodeAll(t *testing.T) {
+	type row struct {
+		Name string `csv:"name"`
+		Data []byte `csv:"data"`
+	}
+	d := csv.NewDecoder(csv.NewReader(strings.NewReader("name,data\na,x\nb,y\n")))
+
+	var rows []row
+	for v, err := range csv.DecodeAll[row](d) {
+		if !assert.NoError(t, err) {
+			return
+		}
+		rows = append(rows, v)
+	}
+	// Each row is decoded into a new value, so the []byte fields are not shared
+	assert.Equal(t, []row{{Name: "a", Data: []byte("x")}, {Name: "b", Data: []byte("y")}}, rows)
+}
+
+func TestDecodeAllError(t *testing.T) {
+	type row struct {
+		Count int `csv:"count"`
+	}
+	d := csv.NewDecoder(csv.NewReader(strings.NewReader("count\n1\nx\n3\n")))
+
+	var counts []int
+	var errs []error
+	for v, err := range csv.DecodeAll[row](d) {
+		if err != nil {
+			assert.Zero(t, v)
+			errs = append(errs, err)
+			continue
+		}
+		counts = append(counts, v.Count)
+	}
+	assert.Equal(t, []int{1}, counts)
+	if assert.Len(t, errs, 1) {
+		assert.EqualError(t, errs[0], `record 3, cell 0 "count" (line 3, offset 8): strconv.ParseInt: parsing "x": invalid syntax`)
+	}
+}
+
+func ExampleDecodeAll() {
+	in := `name,age
+Alice,34
+Bob,27
+`
+	type person struct {
+		Name string `csv:"name"`
+		Age  int    `csv:"age"`
+	}
+
+	d := csv.NewDecoder(csv.NewReader(strings.NewReader(in)))
+	for p, err := range csv.DecodeAll[person](d) {
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Printf("%+v\n", p)
+	}
+
+	// Output: {Name:Alice Age:34}
+	// {Name:Bob Age:27}
+}
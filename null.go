@@ -0,0 +1,96 @@
+package csv
+
+import (
+	"database/sql"
+)
+
+// NullString reads the i-th cell of the current row as a sql.NullString. The value is NULL if the cell is
+// empty and not quoted (see IsNull). Only valid after a call to Read or Scan.
+func (r *Reader) NullString(i int) (sql.NullString, error) {
+	if err := r.checkCell(i); err != nil {
+		return sql.NullString{}, err
+	}
+	if r.IsNull(i) {
+		return sql.NullString{}, nil
+	}
+	return sql.NullString{String: r.Text(i), Valid: true}, nil
+}
+
+// NullInt64 reads the i-th cell of the current row as a sql.NullInt64. The value is NULL if the cell is
+// empty, whether or not it is quoted. Only valid after a call to Read or Scan.
+func (r *Reader) NullInt64(i int) (sql.NullInt64, error) {
+	if err := r.checkCell(i); err != nil {
+		return sql.NullInt64{}, err
+	}
+	if r.IsEmpty(i) {
+		return sql.NullInt64{}, nil
+	}
+	v, err := r.Int64(i)
+	return sql.NullInt64{Int64: v, Valid: err == nil}, err
+}
+
+// NullInt32 reads the i-th cell of the current row as a sql.NullInt32. The value is NULL if the cell is
+// empty, whether or not it is quoted. Only valid after a call to Read or Scan.
+func (r *Reader) NullInt32(i int) (sql.NullInt32, error) {
+	if err := r.checkCell(i); err != nil {
+		return sql.NullInt32{}, err
+	}
+	if r.IsEmpty(i) {
+		return sql.NullInt32{}, nil
+	}
+	v, err := r.Int32(i)
+	return sql.NullInt32{Int32: v, Valid: err == nil}, err
+}
+
+// NullInt16 reads the i-th cell of the current row as a sql.NullInt16. The value is NULL if the cell is
+// empty, whether or not it is quoted. Only valid after a call to Read or Scan.
+func (r *Reader) NullInt16(i int) (sql.NullInt16, error) {
+	if err := r.checkCell(i); err != nil {
+		return sql.NullInt16{}, err
+	}
+	if r.IsEmpty(i) {
+		return sql.NullInt16{}, nil
+	}
+	v, err := r.Int16(i)
+	return sql.NullInt16{Int16: v, Valid: err == nil}, err
+}
+
+// NullFloat64 reads the i-th cell of the current row as a sql.NullFloat64. The value is NULL if the cell is
+// empty, whether or not it is quoted. Only valid after a call to Read or Scan.
+func (r *Reader) NullFloat64(i int) (sql.NullFloat64, error) {
+	if err := r.checkCell(i); err != nil {
+		return sql.NullFloat64{}, err
+	}
+	if r.IsEmpty(i) {
+		return sql.NullFloat64{}, nil
+	}
+	v, err := r.Float(i)
+	return sql.NullFloat64{Float64: v, Valid: err == nil}, err
+}
+
+// NullBool reads the i-th cell of the current row as a sql.NullBool. The value is NULL if the cell is empty,
+// whether or not it is quoted. Only valid after a call to Read or Scan.
+func (r *Reader) NullBool(i int) (sql.NullBool, error) {
+	if err := r.checkCell(i); err != nil {
+		return sql.NullBool{}, err
+	}
+	if r.IsEmpty(i) {
+		return sql.NullBool{}, nil
+	}
+	v, err := r.Bool(i)
+	return sql.NullBool{Bool: v, Valid: err == nil}, err
+}
+
+// NullTime reads the i-th cell of the current row as a sql.NullTime, parsed according to layout as for Time.
+// The value is NULL if the cell is empty, whether or not it is quoted. Only valid after a call to Read or
+// Scan.
+func (r *Reader) NullTime(i int, layout string) (sql.NullTime, error) {
+	if err := r.checkCell(i); err != nil {
+		return sql.NullTime{}, err
+	}
+	if r.IsEmpty(i) {
+		return sql.NullTime{}, nil
+	}
+	v, err := r.Time(i, layout)
+	return sql.NullTime{Time: v, Valid: err == nil}, err
+}
@@ -0,0 +1,134 @@
+package csv_test
+
+import (
+	"bytes"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/philpearl/csv"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadQuotedAndNull(t *testing.T) {
+	r := csv.NewReader(strings.NewReader("a,,\"\",\"b\", \"\"\n"))
+	assert.NoError(t, r.Scan())
+	assert.Equal(t, 5, r.Len())
+
+	var quoted, null []bool
+	for i := range r.Len() {
+		quoted = append(quoted, r.IsQuoted(i))
+		null = append(null, r.IsNull(i))
+	}
+	assert.Equal(t, []bool{false, false, true, true, true}, quoted)
+	assert.Equal(t, []bool{false, true, false, false, false}, null)
+}
+
+func TestReadSQLNull(t *testing.T) {
+	r := csv.NewReader(strings.NewReader("hat,12,13,14,1.5,true,2024-01-02\n" + ",,,,,,\n" + `"","","","","","",""` + "\n"))
+
+	assert.NoError(t, r.Scan())
+	s, err := r.NullString(0)
+	assert.NoError(t, err)
+	assert.Equal(t, sql.NullString{String: "hat", Valid: true}, s)
+	i64, err := r.NullInt64(1)
+	assert.NoError(t, err)
+	assert.Equal(t, sql.NullInt64{Int64: 12, Valid: true}, i64)
+	i32, err := r.NullInt32(2)
+	assert.NoError(t, err)
+	assert.Equal(t, sql.NullInt32{Int32: 13, Valid: true}, i32)
+	i16, err := r.NullInt16(3)
+	assert.NoError(t, err)
+	assert.Equal(t, sql.NullInt16{Int16: 14, Valid: true}, i16)
+	f, err := r.NullFloat64(4)
+	assert.NoError(t, err)
+	assert.Equal(t, sql.NullFloat64{Float64: 1.5, Valid: true}, f)
+	b, err := r.NullBool(5)
+	assert.NoError(t, err)
+	assert.Equal(t, sql.NullBool{Bool: true, Valid: true}, b)
+	tm, err := r.NullTime(6, time.DateOnly)
+	assert.NoError(t, err)
+	assert.Equal(t, sql.NullTime{Time: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), Valid: true}, tm)
+
+	// Empty cells are NULL, except that a quoted empty cell is a valid empty string
+	for _, quoted := range []bool{false, true} {
+		assert.NoError(t, r.Scan())
+		s, err := r.NullString(0)
+		assert.NoError(t, err)
+		assert.Equal(t, sql.NullString{Valid: quoted}, s)
+		i64, err := r.NullInt64(1)
+		assert.NoError(t, err)
+		assert.False(t, i64.Valid)
+		i32, err := r.NullInt32(2)
+		assert.NoError(t, err)
+		assert.False(t, i32.Valid)
+		i16, err := r.NullInt16(3)
+		assert.NoError(t, err)
+		assert.False(t, i16.Valid)
+		f, err := r.NullFloat64(4)
+		assert.NoError(t, err)
+		assert.False(t, f.Valid)
+		b, err := r.NullBool(5)
+		assert.NoError(t, err)
+		assert.False(t, b.Valid)
+		tm, err := r.NullTime(6, time.DateOnly)
+		assert.NoError(t, err)
+		assert.False(t, tm.Valid)
+	}
+}
+
+func TestReadSQLNullError(t *testing.T) {
+	r := csv.NewReader(strings.NewReader("a,b\n"))
+	assert.NoError(t, r.Scan())
+	v, err := r.NullInt64(1)
+	assert.EqualError(t, err, `record 1, cell 1 (line 1, offset 2): strconv.ParseInt: parsing "b": invalid syntax`)
+	assert.False(t, v.Valid)
+}
+
+func TestWriterNull(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []csv.WriterOption
+		exp  string
+	}{
+		{
+			name: "default",
+			exp:  "a,,,\n",
+		},
+		{
+			name: "quote empty",
+			opts: []csv.WriterOption{csv.QuoteEmpty()},
+			exp:  "a,,\"\",\"\"\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var b bytes.Buffer
+			w := csv.NewWriter(&b, test.opts...)
+			w.String("a")
+			w.Null()
+			w.String("")
+			w.Bytes(nil)
+			assert.NoError(t, w.LineComplete())
+			assert.Equal(t, test.exp, b.String())
+
+			// Check the NULLs survive a round trip
+			r := csv.NewReader(&b)
+			assert.NoError(t, r.Scan())
+			var nulls []sql.NullString
+			for i := range r.Len() {
+				s, err := r.NullString(i)
+				assert.NoError(t, err)
+				nulls = append(nulls, s)
+			}
+			assert.Equal(t, []sql.NullString{
+				{String: "a", Valid: true},
+				{},
+				{Valid: len(test.opts) > 0},
+				{Valid: len(test.opts) > 0},
+			}, nulls)
+		})
+	}
+}
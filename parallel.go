@@ -0,0 +1,596 @@
+package csv
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"iter"
+	"math"
+	"runtime"
+	"sync"
+)
+
+// ChunkSize sets the size in bytes of the chunks a ParallelReader splits its input into. The default is 4MB.
+// It has no effect on a Reader.
+func ChunkSize(n int) ReaderOption {
+	return readerOptionFunc(func(r *Reader) {
+		r.chunkSize = n
+	})
+}
+
+// Workers sets the number of goroutines a ParallelReader uses to parse its input. The default is
+// runtime.GOMAXPROCS(0). It has no effect on a Reader.
+func Workers(n int) ReaderOption {
+	return readerOptionFunc(func(r *Reader) {
+		r.workers = n
+	})
+}
+
+// Unordered lets a ParallelReader deliver each Batch as soon as it is parsed, rather than in the order of the
+// input. Records are then not numbered, so Record is zero in errors for the cells of an unordered Batch.
+//
+// An unordered Batch is delivered before the ParallelReader has checked it starts at the start of a record,
+// so the chunk boundaries must be right first time. They are found by counting quotes, which works for any
+// input where quotes only start and end quoted cells or are doubled within them. If the count is thrown by
+// a quote within an unquoted cell or a comment line, the rows of the Batch that starts in the wrong place
+// will be wrong, and Batches reports ErrChunkBoundary once it finds out. It has no effect on a Reader.
+func Unordered() ReaderOption {
+	return readerOptionFunc(func(r *Reader) {
+		r.unordered = true
+	})
+}
+
+// ParallelReader reads a large CSV file using several goroutines. It splits the input into chunks of roughly
+// equal size, finds where the records in each chunk start, and parses the chunks in parallel. The rows are
+// delivered in batches, one for each chunk, and each row is read using the same accessors as a Reader.
+// Create with NewParallelReader.
+//
+// To find the record boundaries the ParallelReader first reads the whole input counting quotes and newlines.
+// Where the count of quotes is thrown, for instance by quotes within unquoted cells, a chunk may be found to
+// start in the middle of a record once the chunk before it is parsed. It is then parsed again from the right
+// place, so the result is the same as for a Reader, just slower.
+type ParallelReader struct {
+	in   io.ReaderAt
+	size int64
+	opts []ReaderOption
+
+	// head reads the header, and anything else that's needed from the start of the input, before we start
+	// parsing chunks.
+	head   *Reader
+	inited bool
+	err    error
+
+	// start is where the records after the header start. line and record are the line number and the count
+	// of records read at that point. fieldCount is the number of cells each record should have, if it is
+	// being checked. empty is set if the header runs to the end of the input, so there are no records.
+	start      int64
+	empty      bool
+	line       int
+	record     int
+	fieldCount int
+
+	chunkSize int
+	workers   int
+	unordered bool
+
+	// batches holds Batches for re-use
+	batches sync.Pool
+}
+
+// NewParallelReader creates a ParallelReader that reads size bytes from in. It takes the same options as
+// NewReader, plus ChunkSize, Workers and Unordered. InputEncoding, OnBadRecord and RetainRaw can't be
+// used, and NewParallelReader panics if any of them is.
+func NewParallelReader(in io.ReaderAt, size int64, opts ...ReaderOption) *ParallelReader {
+	head := NewReader(io.NewSectionReader(in, 0, size), opts...)
+	head.checkSeekable("ParallelReader")
+	if head.onBadRecord != nil {
+		panic("ParallelReader can't be used with OnBadRecord")
+	}
+	if head.keepRaw {
+		panic("ParallelReader can't be used with RetainRaw")
+	}
+	p := &ParallelReader{
+		in:        in,
+		size:      size,
+		opts:      opts,
+		head:      head,
+		chunkSize: head.chunkSize,
+		workers:   head.workers,
+		unordered: head.unordered,
+	}
+	if p.chunkSize <= 0 {
+		p.chunkSize = 4 << 20
+	}
+	if p.workers <= 0 {
+		p.workers = runtime.GOMAXPROCS(0)
+	}
+	p.batches.New = func() any {
+		return &Batch{view: p.newView()}
+	}
+	return p
+}
+
+// Header returns the column names from the header row, reading it if necessary. It returns nil if the
+// ParallelReader is not using a header. The returned slice must not be modified.
+func (p *ParallelReader) Header() ([]string, error) {
+	if err := p.init(); err != nil {
+		return nil, err
+	}
+	return p.head.header, nil
+}
+
+// init reads the header. If every record must have the same number of cells as the first, it also finds out
+// how many cells the first record has so every chunk can be checked.
+func (p *ParallelReader) init() error {
+	if p.inited {
+		return p.err
+	}
+	p.inited = true
+
+	head := p.head
+	if head.useHeader {
+		if p.err = head.readHeader(); p.err != nil {
+			return p.err
+		}
+	}
+	p.start = head.InputOffset()
+	p.empty = head.fileDone
+	p.line = head.line
+	p.record = head.record
+
+	head.readFieldCount()
+	p.fieldCount = head.fieldCount
+	return nil
+}
+
+// chunk is a section of the input. It holds the records that start at or after start and before end. line is
+// the line number at start.
+type chunk struct {
+	index int
+	start int64
+	end   int64
+	line  int
+}
+
+// Batch is the rows from one chunk of the input, in the order they appear in the input. Batches are re-used,
+// so a Batch is only valid until Batches moves on to the next one.
+type Batch struct {
+	chunk
+
+	// The cells of all the rows one after another
+	parsed    []byte
+	cells     []cellSpan
+	quoted    []bool
+	positions []position
+	rows      []batchRow
+
+	// next is where the record after the last row of the chunk starts, or the start of the lines that are
+	// skipped before it. nextLine is the line number at next. eof is set if the last record of the chunk ends
+	// at the end of the input. err is any error that stopped the chunk being parsed.
+	next     int64
+	nextLine int
+	eof      bool
+	err      error
+
+	// recordBase is the number of records before the chunk. It is -1 if that isn't known.
+	recordBase int
+	view       *Reader
+}
+
+// batchRow locates a row within a Batch. cell is the index of its first cell in Batch.cells, Batch.quoted and
+// Batch.positions, and n is the number of cells. record is the record number counting from the start of the
+// chunk. line and offset are where the row starts, and end where it ends.
+type batchRow struct {
+	cell   int
+	n      int
+	record int
+	line   int
+	offset int64
+	end    int64
+}
+
+// Len returns the number of rows in the Batch
+func (b *Batch) Len() int {
+	return len(b.rows)
+}
+
+// Row returns a Reader positioned at the i-th row of the Batch, so the cells of the row can be read using
+// Int, Text, etc. The same Reader is returned by each call to Row on a Batch, and is only valid until the
+// next call. It can't be used to Scan further rows.
+func (b *Batch) Row(i int) *Reader {
+	row := &b.rows[i]
+	v := b.view
+	v.data = b.parsed
+	v.cells = b.cells[row.cell : row.cell+row.n]
+	v.quoted = b.quoted[row.cell : row.cell+row.n]
+	v.positions = b.positions[row.cell : row.cell+row.n]
+	v.srow = v.srow[:0]
+	v.row = v.row[:0]
+	v.record = 0
+	if b.recordBase >= 0 {
+		v.record = b.recordBase + row.record
+	}
+	v.recordLine = row.line
+	v.recordOffset = row.offset
+	v.bufOffset = row.end
+	return v
+}
+
+// add copies the current row of r into the Batch
+func (b *Batch) add(r *Reader) {
+	b.rows = append(b.rows, batchRow{
+		cell:   len(b.cells),
+		n:      r.Len(),
+		record: r.record,
+		line:   r.recordLine,
+		offset: r.recordOffset,
+		end:    r.InputOffset(),
+	})
+	for i := range r.cells {
+		start := len(b.parsed)
+		b.parsed = append(b.parsed, r.cell(i)...)
+		b.cells = append(b.cells, cellSpan{start: start, end: len(b.parsed)})
+	}
+	b.quoted = append(b.quoted, r.quoted...)
+	b.positions = append(b.positions, r.positions...)
+}
+
+// setRecordBase numbers the records in the Batch, including the one in any error. A base of -1 means the
+// records can't be numbered.
+func (b *Batch) setRecordBase(base int) {
+	b.recordBase = base
+	var pe *ParseError
+	if errors.As(b.err, &pe) {
+		if base < 0 {
+			pe.Record = 0
+		} else {
+			pe.Record += base
+		}
+	}
+}
+
+// Batches returns an iterator over the rows of the input in batches, one Batch for each chunk of the input.
+// Unless Unordered is used, the batches are delivered in the order of the input. If an error occurs it is
+// yielded with a nil Batch after the rows before it, and iteration stops. The ParallelReader reads its input
+// only once, so Batches may only be used once.
+func (p *ParallelReader) Batches() iter.Seq2[*Batch, error] {
+	return func(yield func(*Batch, error) bool) {
+		if err := p.init(); err != nil {
+			yield(nil, err)
+			return
+		}
+		if p.empty {
+			return
+		}
+		chunks, err := p.split()
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		// We limit the number of chunks in flight, so the memory we use doesn't depend on the input size
+		done := make(chan struct{})
+		tokens := make(chan struct{}, 2*p.workers)
+		tasks := make(chan *Batch)
+		results := make(chan *Batch)
+		var wg sync.WaitGroup
+		defer func() {
+			close(done)
+			wg.Wait()
+		}()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(tasks)
+			for _, c := range chunks {
+				select {
+				case tokens <- struct{}{}:
+				case <-done:
+					return
+				}
+				b := p.batches.Get().(*Batch)
+				b.chunk = c
+				select {
+				case tasks <- b:
+				case <-done:
+					return
+				}
+			}
+		}()
+
+		for range p.workers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				r := p.newReader()
+				for b := range tasks {
+					p.parse(r, b)
+					select {
+					case results <- b:
+					case <-done:
+						return
+					}
+				}
+			}()
+		}
+
+		if p.unordered {
+			p.deliverUnordered(len(chunks), results, tokens, yield)
+			return
+		}
+		p.deliver(len(chunks), results, tokens, yield)
+	}
+}
+
+// deliver yields the batches in the order of the input, checking each chunk starts where the chunk before it
+// ends.
+func (p *ParallelReader) deliver(n int, results <-chan *Batch, tokens <-chan struct{}, yield func(*Batch, error) bool) {
+	var r *Reader
+	pending := make(map[int]*Batch)
+	next, nextLine, record := p.start, p.line, p.record
+	for i := 0; i < n; {
+		b, ok := pending[i]
+		if !ok {
+			b = <-results
+			pending[b.index] = b
+			continue
+		}
+		delete(pending, i)
+		<-tokens
+
+		if next > b.start {
+			// The chunk starts within the last record of the chunk before, so we parse it again from the
+			// right place.
+			if r == nil {
+				r = p.newReader()
+			}
+			b.start, b.line = next, nextLine
+			p.parse(r, b)
+		}
+
+		b.setRecordBase(record)
+		record += len(b.rows)
+		if len(b.rows) > 0 && !yield(b, nil) {
+			return
+		}
+		if b.err != nil {
+			yield(nil, b.err)
+			return
+		}
+		if b.eof {
+			// Any later chunks start within the last record
+			return
+		}
+		next, nextLine = b.next, b.nextLine
+		p.batches.Put(b)
+		i++
+	}
+}
+
+// deliverUnordered yields the batches as soon as they are parsed, then checks each chunk started where the
+// chunk before it ended. Errors from parsing a chunk are held back until the check is done, as they are
+// meaningless if the chunk started in the wrong place.
+func (p *ParallelReader) deliverUnordered(n int, results <-chan *Batch, tokens <-chan struct{}, yield func(*Batch, error) bool) {
+	type parsed struct {
+		start int64
+		line  int
+		next  int64
+		eof   bool
+		err   error
+	}
+	finished := make(map[int]parsed)
+	next := p.start
+	eof := false
+	for i := 0; i < n; {
+		b := <-results
+		<-tokens
+		b.setRecordBase(-1)
+		if len(b.rows) > 0 && !yield(b, nil) {
+			return
+		}
+		finished[b.index] = parsed{start: b.start, line: b.line, next: b.next, eof: b.eof, err: b.err}
+		p.batches.Put(b)
+
+		for ; i < n; i++ {
+			c, ok := finished[i]
+			if !ok {
+				break
+			}
+			delete(finished, i)
+			if eof || next > c.start {
+				yield(nil, &ParseError{Line: c.line, Col: 1, StartLine: c.line, Offset: c.start, Err: ErrChunkBoundary})
+				return
+			}
+			if c.err != nil {
+				yield(nil, c.err)
+				return
+			}
+			next, eof = c.next, c.eof
+		}
+	}
+}
+
+// Rows returns an iterator over the rows of the input. It yields a Reader positioned at each row in turn, as
+// for Batch.Row. Errors are handled as for Batches.
+func (p *ParallelReader) Rows() iter.Seq2[*Reader, error] {
+	return func(yield func(*Reader, error) bool) {
+		for b, err := range p.Batches() {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for i := range b.Len() {
+				if !yield(b.Row(i), nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// newReader creates a Reader to parse chunks
+func (p *ParallelReader) newReader() *Reader {
+	r := NewReader(nil, p.opts...)
+	r.buf = make([]byte, 0, 64<<10)
+	return r
+}
+
+// newView creates the Reader a Batch uses to present its rows
+func (p *ParallelReader) newView() *Reader {
+	h := p.head
+	return &Reader{
+		fileDone:    true,
+		useHeader:   h.useHeader,
+		headerFold:  h.headerFold,
+		header:      h.header,
+		headerIndex: h.headerIndex,
+		headerCount: h.headerCount,
+	}
+}
+
+// parse parses the records that start within the chunk into the Batch, using r.
+func (p *ParallelReader) parse(r *Reader, b *Batch) {
+	r.SetInput(io.NewSectionReader(p.in, b.start, p.size-b.start))
+	r.bufOffset = b.start
+	r.line = b.line
+	r.lineOffset = b.start
+	r.fieldCount = p.fieldCount
+	r.header = p.head.header
+
+	b.parsed = b.parsed[:0]
+	b.cells = b.cells[:0]
+	b.quoted = b.quoted[:0]
+	b.positions = b.positions[:0]
+	b.rows = b.rows[:0]
+	b.eof = false
+	b.err = nil
+
+	for {
+		next, line, record := r.InputOffset(), r.line, r.record
+		err := r.scanRow()
+		if r.record != record && r.recordOffset >= b.end {
+			// This record belongs to the next chunk. Any error in it will be found there.
+			b.next, b.nextLine = next, line
+			return
+		}
+		if err == io.EOF {
+			// Only skipped lines follow the last record
+			b.next, b.nextLine = next, line
+			return
+		}
+		if err != nil {
+			b.err = err
+			return
+		}
+		b.add(r)
+		if r.fileDone {
+			b.eof = true
+			return
+		}
+	}
+}
+
+// span summarises a section of the input for split. quotes and newlines count the quotes and newlines in the
+// span. after[k] is the offset just after the first newline in the span that is preceded by an even (k = 0)
+// or odd (k = 1) number of quotes within the span, or -1 if there's no such newline. lines[k] counts the
+// newlines up to and including that one.
+type span struct {
+	quotes   int
+	newlines int
+	after    [2]int64
+	lines    [2]int
+}
+
+// split divides the input into chunks. Each chunk after the first starts after the first newline in its
+// span that is preceded by an even number of quotes since the start of the records, so is not within a
+// quoted cell.
+func (p *ParallelReader) split() ([]chunk, error) {
+	n := int((p.size - p.start + int64(p.chunkSize) - 1) / int64(p.chunkSize))
+	spans := make([]span, n)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var firstErr error
+	indexes := make(chan int)
+	for range min(p.workers, n) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			buf := make([]byte, p.chunkSize)
+			for i := range indexes {
+				if err := p.scanSpan(&spans[i], buf, p.start+int64(i)*int64(p.chunkSize)); err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for i := range n {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	chunks := []chunk{{start: p.start, line: p.line}}
+	quotes, lines := 0, p.line
+	for i, s := range spans {
+		k := quotes % 2
+		if i > 0 && s.after[k] >= 0 {
+			chunks = append(chunks, chunk{
+				index: len(chunks),
+				start: s.after[k],
+				line:  lines + s.lines[k],
+			})
+		}
+		quotes += s.quotes
+		lines += s.newlines
+	}
+	for i := range chunks {
+		chunks[i].end = math.MaxInt64
+		if i+1 < len(chunks) {
+			chunks[i].end = chunks[i+1].start
+		}
+	}
+	return chunks, nil
+}
+
+// scanSpan fills in s for the span that starts at offset, using buf to read it.
+func (p *ParallelReader) scanSpan(s *span, buf []byte, offset int64) error {
+	buf = buf[:min(int64(len(buf)), p.size-offset)]
+	n, err := p.in.ReadAt(buf, offset)
+	if n < len(buf) {
+		if err == nil || err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+
+	quote := p.head.quote
+	s.quotes = bytes.Count(buf, []byte{quote})
+	s.newlines = bytes.Count(buf, []byte{'\n'})
+	s.after = [2]int64{-1, -1}
+
+	var quotes, lines, found int
+	for rest := buf; found < 2; {
+		i := bytes.IndexByte(rest, '\n')
+		if i < 0 {
+			break
+		}
+		quotes += bytes.Count(rest[:i], []byte{quote})
+		lines++
+		rest = rest[i+1:]
+		if k := quotes % 2; s.after[k] < 0 {
+			s.after[k] = offset + int64(len(buf)-len(rest))
+			s.lines[k] = lines
+			found++
+		}
+	}
+	return nil
+}
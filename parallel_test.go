@@ -0,0 +1,211 @@
+package csv_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/philpearl/csv"
+	"github.com/stretchr/testify/assert"
+)
+
+func readParallel(in string, opts ...csv.ReaderOption) ([]rowDetail, string) {
+	p := csv.NewParallelReader(strings.NewReader(in), int64(len(in)), opts...)
+	var rows []rowDetail
+	for row, err := range p.Rows() {
+		if err != nil {
+			return rows, err.Error()
+		}
+		rows = append(rows, readRowDetail(row))
+	}
+	return rows, ""
+}
+
+func TestParallelMatchesReader(t *testing.T) {
+	for _, config := range testConfigs {
+		for i, in := range testInputs {
+			expRows, expErr := readSequential(strings.NewReader(in), config.opts...)
+			// Small chunks put chunk boundaries everywhere, including within quoted cells
+			for _, size := range []int{1, 2, 3, 5, 8, 13, 1000} {
+				for _, workers := range []int{1, 3} {
+					t.Run(fmt.Sprintf("%s/%d/%d/%d", config.name, i, size, workers), func(t *testing.T) {
+						opts := append([]csv.ReaderOption{csv.ChunkSize(size), csv.Workers(workers)}, config.opts...)
+						rows, err := readParallel(in, opts...)
+						assert.Equal(t, expRows, rows)
+						assert.Equal(t, expErr, err)
+					})
+				}
+			}
+		}
+	}
+}
+
+func TestParallelUnordered(t *testing.T) {
+	var b strings.Builder
+	for i := range 1000 {
+		fmt.Fprintf(&b, "%d,\"x\ny\",\"\"\"%d\"\n", i, i)
+	}
+	in := b.String()
+
+	p := csv.NewParallelReader(strings.NewReader(in), int64(len(in)), csv.ChunkSize(100), csv.Workers(4), csv.Unordered(), csv.SkipBlankLines())
+	var values []int
+	var batches int
+	for batch, err := range p.Batches() {
+		if !assert.NoError(t, err) {
+			return
+		}
+		batches++
+		for i := range batch.Len() {
+			row := batch.Row(i)
+			v, err := row.Int(0)
+			assert.NoError(t, err)
+			assert.Equal(t, fmt.Sprintf("\"%d", v), row.Text(2))
+			line, _ := row.FieldPos(0)
+			assert.Equal(t, 2*v+1, line)
+			values = append(values, v)
+		}
+	}
+	assert.True(t, batches > 100, "only %d batches", batches)
+	slices.Sort(values)
+	for i, v := range values {
+		assert.Equal(t, i, v)
+	}
+	assert.Len(t, values, 1000)
+
+	// Records aren't numbered
+	p = csv.NewParallelReader(strings.NewReader(in), int64(len(in)), csv.Unordered())
+	for row := range p.Rows() {
+		_, err := row.Int(1)
+		assert.EqualError(t, err, `record 0, cell 1 (line 1, offset 2): strconv.Atoi: parsing "x\ny": invalid syntax`)
+		break
+	}
+}
+
+func TestParallelUnorderedChunkBoundary(t *testing.T) {
+	// The bare quote means the second chunk looks like it starts after "c\n"
+	in := "a\"b\n\"c\nd\",e\nf,g\n"
+	p := csv.NewParallelReader(strings.NewReader(in), int64(len(in)), csv.ChunkSize(6), csv.Unordered())
+	var err error
+	for _, err = range p.Batches() {
+	}
+	assert.True(t, errors.Is(err, csv.ErrChunkBoundary))
+	assert.EqualError(t, err, "record 0, cell 0 (line 3, offset 7): chunk starts within a record")
+
+	// Without Unordered the chunk is parsed again
+	rows, errText := readParallel(in, csv.ChunkSize(6))
+	assert.Empty(t, errText)
+	expRows, _ := readSequential(strings.NewReader(in))
+	assert.Equal(t, expRows, rows)
+}
+
+func TestParallelHeader(t *testing.T) {
+	in := "name,n\nhat,1\ncoat,2\n\"big\nboots\",3\nscarf,4"
+	p := csv.NewParallelReader(strings.NewReader(in), int64(len(in)), csv.UseHeader(), csv.ChunkSize(4))
+
+	header, err := p.Header()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"name", "n"}, header)
+
+	var names []string
+	var total int
+	for row, err := range p.Rows() {
+		if !assert.NoError(t, err) {
+			return
+		}
+		name, err := row.TextByName("name")
+		assert.NoError(t, err)
+		names = append(names, name)
+		n, err := row.IntByName("n")
+		assert.NoError(t, err)
+		total += n
+	}
+	assert.Equal(t, []string{"hat", "coat", "big\nboots", "scarf"}, names)
+	assert.Equal(t, 10, total)
+}
+
+func TestParallelHeaderError(t *testing.T) {
+	in := "a,a\n1,2\n"
+	p := csv.NewParallelReader(strings.NewReader(in), int64(len(in)), csv.UniqueHeader())
+	_, err := p.Header()
+	assert.True(t, errors.Is(err, csv.ErrDuplicateColumn))
+	for _, err := range p.Batches() {
+		assert.True(t, errors.Is(err, csv.ErrDuplicateColumn))
+	}
+}
+
+func TestParallelBreak(t *testing.T) {
+	in := strings.Repeat("a,b\n", 10000)
+	p := csv.NewParallelReader(strings.NewReader(in), int64(len(in)), csv.ChunkSize(64), csv.Workers(4))
+	var n int
+	for range p.Rows() {
+		n++
+		if n == 100 {
+			break
+		}
+	}
+	assert.Equal(t, 100, n)
+}
+
+type failingReaderAt struct{}
+
+func (failingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	return 0, errors.New("disk on fire")
+}
+
+func TestParallelReadError(t *testing.T) {
+	p := csv.NewParallelReader(failingReaderAt{}, 100)
+	var err error
+	for _, err = range p.Batches() {
+	}
+	assert.EqualError(t, err, "disk on fire")
+}
+
+func TestParallelShortInput(t *testing.T) {
+	in := strings.NewReader("a,b\n")
+	p := csv.NewParallelReader(in, 100, csv.ChunkSize(10))
+	var err error
+	for _, err = range p.Batches() {
+	}
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+}
+
+func ExampleParallelReader() {
+	in := strings.NewReader("name,age\nAlice,34\nBob,27\n")
+	p := csv.NewParallelReader(in, in.Size(), csv.UseHeader(), csv.SkipBlankLines())
+	for row, err := range p.Rows() {
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		age, _ := row.IntByName("age")
+		fmt.Println(row.Text(0), age)
+	}
+
+	// Output: Alice 34
+	// Bob 27
+}
+
+func BenchmarkParallelRead(b *testing.B) {
+	content := bytes.Repeat([]byte("cheese, feet, lemon, 99, 1002, \"12\n98\", 12.3, 17, 11, whale\n"), 1<<16)
+	in := bytes.NewReader(content)
+
+	b.SetBytes(int64(len(content)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		p := csv.NewParallelReader(in, in.Size(), csv.ChunkSize(256<<10), csv.SkipBlankLines())
+		for row, err := range p.Rows() {
+			if err != nil {
+				b.Fatal(err)
+			}
+			if _, err := row.Int(3); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
@@ -1,9 +1,13 @@
 package csv
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"io"
+	"slices"
 	"strconv"
+	"strings"
 	"unsafe"
 )
 
@@ -13,11 +17,37 @@ type Reader struct {
 	buf []byte // Buffer we're reading into
 	pos int    // position in buf
 
-	// We copy cell content into parsed as we process it. parsed will contain all the cells of a row one after
-	// another. parsed is re-used between rows
-	parsed []byte
-	// Offsets of cell boundaries within parsed. First offset is always zero
-	cellOffsets []int
+	// encoding converts the input to UTF-8. See InputEncoding
+	encoding Encoding
+	// decompressor is used by SetCompressedInput
+	decompressor *decompressor
+	// ctx is the context passed to ScanContext while it runs
+	ctx context.Context
+
+	// bufOffset is the offset of buf within the input. line is the current line number, counting from 1, and
+	// lineOffset is the offset where it starts. record counts the records we've read. recordLine and
+	// recordOffset are the line and offset where the current record starts.
+	bufOffset    int64
+	line         int
+	lineOffset   int64
+	record       int
+	recordLine   int
+	recordOffset int64
+
+	// Cells are left where they are in buf if we can. If a cell needs unescaping, or a row is split across
+	// reads, the row is copied into parsed instead. data is whichever of buf and parsed holds the current
+	// row, and cells records where each cell is within it. copying is set once the row is being copied.
+	// parsed is re-used between rows
+	data    []byte
+	parsed  []byte
+	cells   []cellSpan
+	copying bool
+	// quoted records whether each cell was quoted. cellQuoted is set by scanCell if the cell it is scanning is
+	// quoted
+	quoted     []bool
+	cellQuoted bool
+	// positions records where each cell starts
+	positions []position
 
 	// The current row as a slice of []bytes or a slice of strings. These are re-used between rows.
 	row  [][]byte
@@ -25,41 +55,337 @@ type Reader struct {
 
 	rowDone  bool
 	fileDone bool
+
+	// The field delimiter and quote character. stop marks the bytes that end the content of an unquoted cell
+	// or need special handling within it
+	delim byte
+	quote byte
+	stop  [256]bool
+
+	// How strictly we parse. See Strict, LazyQuotes, TrimLeadingSpace and KeepWhitespace
+	trimSpace  bool
+	keepSpace  bool
+	bareQuotes bool
+	lazyQuotes bool
+	strict     bool
+	// compat is set if we follow the rules of encoding/csv. See StdlibCompatible
+	compat bool
+
+	// Lines we skip. See Comment and SkipBlankLines
+	comment   byte
+	skipBlank bool
+
+	// The number of cells we expect in each record. See FieldsPerRecord. fieldCount is the number we expect
+	// in the next record, which is set by the first record if fieldsPerRecord is zero
+	checkFields     bool
+	fieldsPerRecord int
+	fieldCount      int
+
+	// Limits on the size of rows. See MaxCellSize, MaxRowSize and MaxCellsPerRow
+	maxCellSize int
+	maxRowSize  int
+	maxCells    int
+
+	// Bad record handling. See OnBadRecord. keepRaw is set if fill must keep the raw input of the current
+	// record in buf, which is needed for OnBadRecord and RetainRaw. rawScratch holds the raw input of a bad
+	// record that is too large to keep
+	onBadRecord func(bad BadRecord) error
+	keepRaw     bool
+	skipped     int
+	rawScratch  []byte
+
+	// Settings for ParallelReader. See ChunkSize, Workers and Unordered
+	chunkSize int
+	workers   int
+	unordered bool
+
+	// Header handling. See UseHeader
+	useHeader    bool
+	headerFold   bool
+	headerUnique bool
+	required     []string
+	header       []string
+	headerIndex  map[string]int
+	// headerErr is set if the header is read but is invalid. It is returned by every later Scan
+	headerErr error
+	// headerCount counts the headers we've read, so users of the header can tell when it changes
+	headerCount int
 }
 
-// NewReader creates a new CSV file reader
-func NewReader(r io.Reader) *Reader {
-	return &Reader{
-		r:   r,
-		buf: make([]byte, 0, 4096),
+// NewReader creates a new CSV file reader. By default it reads comma separated data quoted with '"'. Pass a
+// Dialect to read other formats. A UTF-8 byte order mark at the start of the input is skipped. NewReader
+// panics if the Dialect or comment character is invalid.
+func NewReader(r io.Reader, opts ...ReaderOption) *Reader {
+	rd := &Reader{
+		r:     r,
+		buf:   make([]byte, 0, 4096),
+		line:  1,
+		delim: DefaultDialect.Comma,
+		quote: DefaultDialect.Quote,
+
+		trimSpace:  true,
+		bareQuotes: true,
+	}
+	for _, opt := range opts {
+		opt.applyReader(rd)
 	}
+	rd.fieldCount = rd.fieldsPerRecord
+	rd.stop[rd.delim], rd.stop['\n'], rd.stop['\r'] = true, true, true
+	rd.stop[rd.quote] = !rd.bareQuotes
+	if c := rd.comment; c == rd.delim || c == rd.quote || c == '\r' || c == '\n' {
+		panic(fmt.Sprintf("invalid comment character %q", c))
+	}
+	return rd
 }
 
 // SetInput lets you use an existing Reader with a new input file.
 func (r *Reader) SetInput(in io.Reader) {
+	if r.encoding != nil {
+		in = r.encoding(in)
+	}
 	r.r = in
 	r.pos = 0
 	r.buf = r.buf[:0]
-	r.rowDone = false
+	// No record is in progress, so fill won't keep anything while comments and blank lines are skipped
+	r.rowDone = true
 	r.fileDone = false
+	r.header = nil
+	r.headerErr = nil
+	r.bufOffset = 0
+	r.line = 1
+	r.lineOffset = 0
+	r.record = 0
+	r.recordOffset = 0
+	r.skipped = 0
+	r.fieldCount = r.fieldsPerRecord
+}
+
+// readFieldCount reads ahead to learn the number of cells each record should have, if that is set by the
+// first record. It is used before reading records from the middle of the input. Any error here will be found
+// again when the record is read.
+func (r *Reader) readFieldCount() {
+	if r.checkFields {
+		// The empty record at the end of the input doesn't set the count, so we may need to look past it
+		for r.fieldCount == 0 && r.scanRow() == nil {
+		}
+	}
+}
+
+// checkSeekable panics if the Reader can't be used by what, which reads from the middle of the input.
+// Offsets in the input can't be used if it is converted with InputEncoding.
+func (r *Reader) checkSeekable(what string) {
+	if r.encoding != nil {
+		panic(what + " can't be used with InputEncoding")
+	}
+}
+
+// cellSpan is where a cell's content is within Reader.data
+type cellSpan struct {
+	start int
+	end   int
+}
+
+// position is where a cell starts. offset is the byte offset in the input. line and column both count from
+// 1, and the column is in bytes.
+type position struct {
+	offset int64
+	line   int
+	column int
+}
+
+// FieldPos returns the line and column where the i-th cell of the current row starts. Both count from 1, and
+// the column is measured in bytes. If the cell is quoted this is the position of the opening quote. Only
+// valid after a call to Read or Scan.
+func (r *Reader) FieldPos(i int) (line, column int) {
+	p := r.positions[i]
+	return p.line, p.column
+}
+
+// CellOffset returns the byte offset in the input where the i-th cell of the current row starts. If the cell
+// is quoted this is the offset of the opening quote. Only valid after a call to Read or Scan.
+func (r *Reader) CellOffset(i int) int64 {
+	return r.positions[i].offset
+}
+
+// RecordOffset returns the byte offset in the input where the current row starts. Only valid after a call to
+// Read or Scan.
+func (r *Reader) RecordOffset() int64 {
+	return r.recordOffset
+}
+
+// InputOffset returns the byte offset in the input of the end of the current row, which is also the start of
+// the next row.
+func (r *Reader) InputOffset() int64 {
+	return r.bufOffset + int64(r.pos)
+}
+
+// RetainRaw makes the Reader keep the input of the current record, which is available from RawRecord. The
+// record is kept however many reads it spans, so you may want to limit its size with MaxRowSize.
+func RetainRaw() ReaderOption {
+	return readerOptionFunc(func(r *Reader) {
+		r.keepRaw = true
+	})
+}
+
+// RawRecord returns the input of the current record exactly as it was read, from RecordOffset to
+// InputOffset. It includes quotes and the line ending, if any, but not comment or blank lines that were
+// skipped before the record. If InputEncoding is used it is the input after conversion to UTF-8. RawRecord
+// returns nil unless RetainRaw is used. Only valid after a call to Read or Scan, and the contents are only
+// valid until the next call to Read, Scan or Bytes.
+func (r *Reader) RawRecord() []byte {
+	start := r.recordOffset - r.bufOffset
+	if !r.keepRaw || start < 0 || start > int64(r.pos) {
+		return nil
+	}
+	return r.buf[start:r.pos]
 }
 
 // Int reads the i-th cell of the current row as an int. Only valid after a call to Read or Scan.
 func (r *Reader) Int(i int) (int, error) {
-	b := r.parsed[r.cellOffsets[i]:r.cellOffsets[i+1]]
-	return strconv.Atoi(*(*string)(unsafe.Pointer(&b)))
+	if err := r.checkCell(i); err != nil {
+		return 0, err
+	}
+	b := r.cell(i)
+	v, err := strconv.Atoi(*(*string)(unsafe.Pointer(&b)))
+	if err != nil {
+		return v, r.cellError(i, err)
+	}
+	return v, nil
 }
 
 // Float reads the i-th cell of the current row as a float. Only valid after a call to Read or Scan.
 func (r *Reader) Float(i int) (float64, error) {
-	b := r.parsed[r.cellOffsets[i]:r.cellOffsets[i+1]]
-	return strconv.ParseFloat(*(*string)(unsafe.Pointer(&b)), 64)
+	if err := r.checkCell(i); err != nil {
+		return 0, err
+	}
+	b := r.cell(i)
+	v, err := strconv.ParseFloat(*(*string)(unsafe.Pointer(&b)), 64)
+	if err != nil {
+		return v, r.cellError(i, err)
+	}
+	return v, nil
 }
 
 // Bool reads the i-th cell of the current row as a boolean value. Only valid after a call to Read or Scan.
 func (r *Reader) Bool(i int) (bool, error) {
-	b := r.parsed[r.cellOffsets[i]:r.cellOffsets[i+1]]
-	return strconv.ParseBool(*(*string)(unsafe.Pointer(&b)))
+	if err := r.checkCell(i); err != nil {
+		return false, err
+	}
+	b := r.cell(i)
+	v, err := strconv.ParseBool(*(*string)(unsafe.Pointer(&b)))
+	if err != nil {
+		return v, r.cellError(i, err)
+	}
+	return v, nil
+}
+
+// Int64 reads the i-th cell of the current row as an int64. Only valid after a call to Read or Scan.
+func (r *Reader) Int64(i int) (int64, error) {
+	return r.parseInt(i, 64)
+}
+
+// Int32 reads the i-th cell of the current row as an int32. An error is returned if the value is out of
+// range. Only valid after a call to Read or Scan.
+func (r *Reader) Int32(i int) (int32, error) {
+	v, err := r.parseInt(i, 32)
+	return int32(v), err
+}
+
+// Int16 reads the i-th cell of the current row as an int16. An error is returned if the value is out of
+// range. Only valid after a call to Read or Scan.
+func (r *Reader) Int16(i int) (int16, error) {
+	v, err := r.parseInt(i, 16)
+	return int16(v), err
+}
+
+// Int8 reads the i-th cell of the current row as an int8. An error is returned if the value is out of
+// range. Only valid after a call to Read or Scan.
+func (r *Reader) Int8(i int) (int8, error) {
+	v, err := r.parseInt(i, 8)
+	return int8(v), err
+}
+
+// Uint64 reads the i-th cell of the current row as a uint64. Only valid after a call to Read or Scan.
+func (r *Reader) Uint64(i int) (uint64, error) {
+	return r.parseUint(i, 64)
+}
+
+// Uint32 reads the i-th cell of the current row as a uint32. An error is returned if the value is out of
+// range. Only valid after a call to Read or Scan.
+func (r *Reader) Uint32(i int) (uint32, error) {
+	v, err := r.parseUint(i, 32)
+	return uint32(v), err
+}
+
+// Uint16 reads the i-th cell of the current row as a uint16. An error is returned if the value is out of
+// range. Only valid after a call to Read or Scan.
+func (r *Reader) Uint16(i int) (uint16, error) {
+	v, err := r.parseUint(i, 16)
+	return uint16(v), err
+}
+
+// Uint8 reads the i-th cell of the current row as a uint8. An error is returned if the value is out of
+// range. Only valid after a call to Read or Scan.
+func (r *Reader) Uint8(i int) (uint8, error) {
+	v, err := r.parseUint(i, 8)
+	return uint8(v), err
+}
+
+// Float32 reads the i-th cell of the current row as a float32. An error is returned if the value is out of
+// range. Only valid after a call to Read or Scan.
+func (r *Reader) Float32(i int) (float32, error) {
+	if err := r.checkCell(i); err != nil {
+		return 0, err
+	}
+	b := r.cell(i)
+	v, err := strconv.ParseFloat(*(*string)(unsafe.Pointer(&b)), 32)
+	if err != nil {
+		return float32(v), r.cellError(i, err)
+	}
+	return float32(v), nil
+}
+
+func (r *Reader) parseInt(i, bitSize int) (int64, error) {
+	if err := r.checkCell(i); err != nil {
+		return 0, err
+	}
+	b := r.cell(i)
+	v, err := strconv.ParseInt(*(*string)(unsafe.Pointer(&b)), 10, bitSize)
+	if err != nil {
+		return v, r.cellError(i, err)
+	}
+	return v, nil
+}
+
+func (r *Reader) parseUint(i, bitSize int) (uint64, error) {
+	if err := r.checkCell(i); err != nil {
+		return 0, err
+	}
+	b := r.cell(i)
+	v, err := strconv.ParseUint(*(*string)(unsafe.Pointer(&b)), 10, bitSize)
+	if err != nil {
+		return v, r.cellError(i, err)
+	}
+	return v, nil
+}
+
+// Cell returns the raw parsed bytes for the i-th cell of the current row, as for Raw. Unlike Raw it returns
+// an error wrapping ErrNoCell rather than panicking if there is no i-th cell. Only valid after a call to Read
+// or Scan.
+func (r *Reader) Cell(i int) ([]byte, error) {
+	if err := r.checkCell(i); err != nil {
+		return nil, err
+	}
+	return r.Raw(i), nil
+}
+
+// CellText reads the i-th cell of the current row as a string, as for Text. Unlike Text it returns an error
+// wrapping ErrNoCell rather than panicking if there is no i-th cell. Only valid after a call to Read or Scan.
+func (r *Reader) CellText(i int) (string, error) {
+	if err := r.checkCell(i); err != nil {
+		return "", err
+	}
+	return r.Text(i), nil
 }
 
 // Text reads the i-th cell of the current row as a string. Only valid after a call to Read or Scan.
@@ -71,12 +397,30 @@ func (r *Reader) Text(i int) string {
 // valid after a call to Read or Scan. The contents is only valid until the next
 // call to Read, Scan or Bytes.
 func (r *Reader) Raw(i int) []byte {
-	return r.parsed[r.cellOffsets[i]:r.cellOffsets[i+1]]
+	return r.cell(i)
+}
+
+func (r *Reader) cell(i int) []byte {
+	c := r.cells[i]
+	return r.data[c.start:c.end]
 }
 
 // IsEmpty returns true if the i-th cell of the current row is empty. Only valid after a call to Read or Scan.
 func (r *Reader) IsEmpty(i int) bool {
-	return r.cellOffsets[i] == r.cellOffsets[i+1]
+	c := r.cells[i]
+	return c.start == c.end
+}
+
+// IsQuoted returns true if the i-th cell of the current row was quoted in the input. Only valid after a call
+// to Read or Scan.
+func (r *Reader) IsQuoted(i int) bool {
+	return r.quoted[i]
+}
+
+// IsNull returns true if the i-th cell of the current row is empty and was not quoted. This distinguishes
+// `,,` (NULL) from `,"",` (an empty string), as Postgres does. Only valid after a call to Read or Scan.
+func (r *Reader) IsNull(i int) bool {
+	return r.IsEmpty(i) && !r.quoted[i]
 }
 
 // Read returns the entire next line of the CSV file as a []string. The slice is only valid until the next
@@ -93,11 +437,21 @@ func (r *Reader) rowStrings() []string {
 	if len(r.srow) != 0 {
 		return r.srow
 	}
-	s := string(r.parsed)
-	lastOffset := 0
-	for _, offset := range r.cellOffsets[1:] {
-		r.srow = append(r.srow, s[lastOffset:offset])
-		lastOffset = offset
+	// We make a single string of all the cells, then slice it up
+	n := 0
+	for _, c := range r.cells {
+		n += c.end - c.start
+	}
+	var b strings.Builder
+	b.Grow(n)
+	for i := range r.cells {
+		b.Write(r.cell(i))
+	}
+	s := b.String()
+	for _, c := range r.cells {
+		n := c.end - c.start
+		r.srow = append(r.srow, s[:n])
+		s = s[n:]
 	}
 	return r.srow
 }
@@ -109,10 +463,8 @@ func (r *Reader) Bytes() ([][]byte, error) {
 		return nil, err
 	}
 
-	lastOffset := 0
-	for _, offset := range r.cellOffsets[1:] {
-		r.row = append(r.row, r.parsed[lastOffset:offset])
-		lastOffset = offset
+	for i := range r.cells {
+		r.row = append(r.row, r.cell(i))
 	}
 
 	return r.row, nil
@@ -128,56 +480,433 @@ const (
 	cellStateInCell
 	cellStateTrailingWhiteSpace
 	cellStateSlashR
+	cellStateInQuoteSlashR
+	cellStateQuoteSlashR
 )
 
 // Scan reads the next row of the CSV. You can then access cells in the row using Int, Float, Bool or Text.
 func (r *Reader) Scan() error {
+	if err := r.checkHeader(); err != nil {
+		return err
+	}
+	for {
+		err := r.scanRow()
+		if err == nil || r.onBadRecord == nil {
+			return err
+		}
+		if err := r.skipBadRecord(err); err != nil {
+			return err
+		}
+	}
+}
+
+// ScanContext is Scan, but returns ctx.Err() if ctx is done before the row is read. ctx is checked before the
+// row is started and before each read from the input, but a read that is already in progress is not
+// interrupted. If ctx is done part way through a row the rest of the row is still to be read, so the Reader
+// should not be used further.
+func (r *Reader) ScanContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.ctx = ctx
+	defer func() { r.ctx = nil }()
+	return r.Scan()
+}
+
+func (r *Reader) scanRow() error {
 	if r.fileDone {
 		return io.EOF
 	}
 
-	r.parsed = r.parsed[:0]
+	if r.record == 0 && r.bufOffset+int64(r.pos) == 0 && !r.compat {
+		if err := r.skipBOM(); err != nil {
+			return err
+		}
+	}
+
+	if r.comment != 0 || r.skipBlank {
+		if err := r.skipLines(); err != nil {
+			if err == io.EOF {
+				r.fileDone = true
+			}
+			return err
+		}
+	}
+
+	r.record++
+	r.recordLine = r.line
+	r.recordOffset = r.bufOffset + int64(r.pos)
+
+	r.data = r.buf
+	r.copying = false
 	r.rowDone = false
 	r.srow = r.srow[:0]
 	r.row = r.row[:0]
-	r.cellOffsets = r.cellOffsets[:0]
-	r.cellOffsets = append(r.cellOffsets, 0)
+	r.cells = r.cells[:0]
+	r.quoted = r.quoted[:0]
+	r.positions = r.positions[:0]
 
 	for !r.rowDone {
-		if err := r.scanCell(); err != nil {
-			return err
+		if r.maxCells > 0 && len(r.cells) == r.maxCells {
+			return r.parseError(fmt.Errorf("%w (limit %d)", ErrTooManyCells, r.maxCells), 0)
 		}
-		r.cellOffsets = append(r.cellOffsets, len(r.parsed))
+		r.cellQuoted = false
+		offset := r.bufOffset + int64(r.pos)
+		r.positions = append(r.positions, position{
+			offset: offset,
+			line:   r.line,
+			column: int(offset-r.lineOffset) + 1,
+		})
+
+		var c cellSpan
+		if r.copying || !r.scanPlainCell(&c) {
+			if !r.copying {
+				r.copyRow()
+			}
+			c.start = len(r.parsed)
+			if err := r.scanCell(); err != nil {
+				return err
+			}
+			c.end = len(r.parsed)
+			r.data = r.parsed
+		}
+		if r.maxCellSize > 0 || r.maxRowSize > 0 {
+			if err := r.checkLimits(c.end - c.start); err != nil {
+				return err
+			}
+		}
+		r.cells = append(r.cells, c)
+		r.quoted = append(r.quoted, r.cellQuoted)
 	}
 
+	if r.checkFields {
+		return r.checkFieldCount()
+	}
 	return nil
 }
 
+// checkFieldCount checks the current row has the expected number of cells. The empty record after a line
+// ending at the end of the input is not checked. It has no input, which no other record can have.
+func (r *Reader) checkFieldCount() error {
+	n := r.Len()
+	switch {
+	case r.recordOffset == r.InputOffset():
+		return nil
+	case r.fieldCount == 0:
+		r.fieldCount = n
+		return nil
+	case n == r.fieldCount:
+		return nil
+	}
+	return r.recordError(min(n, r.fieldCount), fmt.Errorf("%w: have %d, want %d", ErrFieldCount, n, r.fieldCount))
+}
+
 // Len returns the number of cells in the current row. This is valid only after a call to Scan, Bytes or Read
 func (r *Reader) Len() int {
-	return len(r.cellOffsets) - 1
+	return len(r.cells)
+}
+
+// copyRow copies the cells of the current row we've read so far into parsed, so the rest of the row can be
+// copied after them.
+func (r *Reader) copyRow() {
+	r.parsed = r.parsed[:0]
+	for i, c := range r.cells {
+		start := len(r.parsed)
+		r.parsed = append(r.parsed, r.buf[c.start:c.end]...)
+		r.cells[i] = cellSpan{start: start, end: len(r.parsed)}
+	}
+	r.data = r.parsed
+	r.copying = true
+}
+
+// checkLimits returns an error if the current cell, which has n bytes so far, or the current row is larger than
+// allowed. See MaxCellSize and MaxRowSize.
+func (r *Reader) checkLimits(n int) error {
+	if r.maxCellSize > 0 && n > r.maxCellSize {
+		return r.cellError(len(r.cells), fmt.Errorf("%w (limit %d)", ErrCellTooLarge, r.maxCellSize))
+	}
+	if r.maxRowSize > 0 && r.bufOffset+int64(r.pos)-r.recordOffset > int64(r.maxRowSize) {
+		return r.recordError(len(r.cells), fmt.Errorf("%w (limit %d)", ErrRowTooLarge, r.maxRowSize))
+	}
+	return nil
+}
+
+// scanPlainCell scans the next cell without copying it, if the cell is entirely within buf and its content
+// doesn't need unescaping. This covers unquoted cells and quoted cells that contain no quotes. The cell is
+// returned in c as a span of buf. If the cell can't be scanned this way scanPlainCell returns false and
+// leaves the Reader as it was, so scanCell can be used instead.
+func (r *Reader) scanPlainCell(c *cellSpan) bool {
+	buf, delim, quote := r.buf, r.delim, r.quote
+	i := r.pos
+	if r.trimSpace {
+		// The delimiter may itself be a space or tab, in which case it ends the cell
+		for i < len(buf) && buf[i] != delim && (buf[i] == ' ' || buf[i] == '\t') {
+			i++
+		}
+	}
+	if i >= len(buf) {
+		return false
+	}
+
+	start, end, next := i, i, i
+	quoted := buf[i] == quote
+	if quoted {
+		j := bytes.IndexByte(buf[i+1:], quote)
+		if j < 0 {
+			return false
+		}
+		start, end = i+1, i+1+j
+		next = end + 1
+		if r.compat && bytes.IndexByte(buf[start:end], '\r') >= 0 {
+			// "\r\n" would be read as "\n"
+			return false
+		}
+	} else {
+		stop := &r.stop
+		for next < len(buf) && !stop[buf[next]] {
+			next++
+		}
+		end = next
+	}
+
+	// Now we should be at the end of the cell
+	rowDone := false
+	switch {
+	case next >= len(buf):
+		return false
+	case buf[next] == delim:
+		next++
+	case buf[next] == '\n':
+		next++
+		rowDone = true
+	case buf[next] == '\r' && next+1 < len(buf) && buf[next+1] == '\n':
+		next += 2
+		rowDone = true
+	default:
+		return false
+	}
+
+	if quoted {
+		if k := bytes.LastIndexByte(buf[start:end], '\n'); k >= 0 {
+			r.line += bytes.Count(buf[start:end], []byte{'\n'})
+			r.lineOffset = r.bufOffset + int64(start+k+1)
+		}
+		r.cellQuoted = true
+	}
+	if skipped := i - r.pos; skipped > 0 {
+		p := &r.positions[len(r.positions)-1]
+		p.offset += int64(skipped)
+		p.column += skipped
+	}
+	r.pos = next
+	r.rowDone = rowDone
+	if rowDone {
+		r.newLine()
+	}
+	*c = cellSpan{start: start, end: end}
+	return true
+}
+
+// fill reads more data into buf once everything in it has been consumed. If keepRaw is set the part of the
+// current record that's in buf is kept at the start of it.
+func (r *Reader) fill() error {
+	keep := 0
+	if r.keepRaw && !r.rowDone {
+		if start := int(r.recordOffset - r.bufOffset); start >= 0 && start < len(r.buf) {
+			keep = copy(r.buf, r.buf[start:])
+		}
+	}
+	r.bufOffset += int64(len(r.buf) - keep)
+	if keep > cap(r.buf)/2 {
+		r.buf = slices.Grow(r.buf[:keep], cap(r.buf))
+	}
+	r.pos = keep
+	r.buf = r.buf[:cap(r.buf)]
+	n, err := r.read(r.buf[keep:])
+	r.buf = r.buf[:keep+n]
+	if n == 0 {
+		return err
+	}
+	return nil
+}
+
+// read reads from the input, unless the context passed to ScanContext is done
+func (r *Reader) read(p []byte) (int, error) {
+	if r.ctx != nil {
+		if err := r.ctx.Err(); err != nil {
+			return 0, err
+		}
+	}
+	return r.r.Read(p)
+}
+
+// peek returns the next n bytes of input without consuming them. It returns fewer bytes only if there's an
+// error. Unconsumed data is moved to the start of buf to make room, so peek must only be used between rows.
+func (r *Reader) peek(n int) ([]byte, error) {
+	for len(r.buf)-r.pos < n {
+		m := copy(r.buf[:cap(r.buf)], r.buf[r.pos:])
+		r.bufOffset += int64(r.pos)
+		r.pos = 0
+		k, err := r.read(r.buf[m:cap(r.buf)])
+		r.buf = r.buf[:m+k]
+		if k == 0 && err != nil {
+			return r.buf[r.pos:], err
+		}
+	}
+	return r.buf[r.pos : r.pos+n], nil
+}
+
+// skipLines skips any comment lines and, if required, blank lines before the next record. It returns
+// io.EOF if it reaches the end of the input after skipping lines, or if it finds the input is empty and
+// blank lines are being skipped.
+func (r *Reader) skipLines() error {
+	skipped := false
+	for {
+		b, err := r.peek(2)
+		if len(b) == 0 {
+			if err == io.EOF && !skipped && !r.skipBlank {
+				// Let the caller make a row of the empty input
+				return nil
+			}
+			return err
+		}
+		switch {
+		case r.skipBlank && b[0] == '\n':
+			r.pos++
+		case r.skipBlank && len(b) == 2 && b[0] == '\r' && b[1] == '\n':
+			r.pos += 2
+		case r.skipBlank && len(b) == 1 && b[0] == '\r':
+			// A '\r' at the end of the input is dropped, as it would be at the end of a row
+			r.pos++
+			continue
+		case b[0] == r.comment && r.comment != 0:
+			if err := r.skipLine(); err != nil {
+				return err
+			}
+			skipped = true
+			continue
+		default:
+			return nil
+		}
+		r.newLine()
+		skipped = true
+	}
+}
+
+// newLine records that we've just consumed a '\n'
+func (r *Reader) newLine() {
+	r.line++
+	r.lineOffset = r.bufOffset + int64(r.pos)
+}
+
+// skipLine skips to the start of the next line
+func (r *Reader) skipLine() error {
+	for {
+		if i := bytes.IndexByte(r.buf[r.pos:], '\n'); i >= 0 {
+			r.pos += i + 1
+			r.newLine()
+			return nil
+		}
+		r.pos = len(r.buf)
+		if err := r.fill(); err != nil {
+			return err
+		}
+	}
+}
+
+// skipRecord skips to the start of the record after the current one. It reads the record again from its start
+// with the rules used for LazyQuotes, so a quoted cell that continues past the end of the line is skipped as
+// a whole. The start of the record must still be in buf.
+func (r *Reader) skipRecord() error {
+	const (
+		skipBegin = iota
+		skipInCell
+		skipInQuote
+		skipInQuoteQuote
+		skipQuoteSlashR
+	)
+	s := skipBegin
+	r.pos = int(r.recordOffset - r.bufOffset)
+	r.line = r.recordLine
+	for {
+		if r.pos >= len(r.buf) {
+			if err := r.fill(); err != nil {
+				return err
+			}
+		}
+		c := r.buf[r.pos]
+		r.pos++
+		if c == '\n' {
+			r.newLine()
+			if s != skipInQuote {
+				return nil
+			}
+			continue
+		}
+		switch s {
+		case skipBegin:
+			switch {
+			case c == r.quote:
+				s = skipInQuote
+			case c == r.delim, r.trimSpace && (c == ' ' || c == '\t'):
+			default:
+				s = skipInCell
+			}
+		case skipInCell:
+			if c == r.delim {
+				s = skipBegin
+			}
+		case skipInQuote:
+			if c == r.quote {
+				s = skipInQuoteQuote
+			}
+		case skipInQuoteQuote:
+			switch c {
+			case r.delim:
+				s = skipBegin
+			case '\r':
+				s = skipQuoteSlashR
+			default:
+				// Either a quoted quote, or a quote that's just part of the cell
+				s = skipInQuote
+			}
+		case skipQuoteSlashR:
+			switch {
+			case c == r.quote:
+				s = skipInQuoteQuote
+			case r.compat:
+				s = skipInQuote
+			case c == r.delim:
+				s = skipBegin
+			default:
+				s = skipInCell
+			}
+		}
+	}
 }
 
 func (r *Reader) scanCell() error {
 	var s cellState
+	delim, quote := r.delim, r.quote
+	start := len(r.parsed)
 
 	for {
 		if r.pos >= len(r.buf) {
-			r.buf = r.buf[:cap(r.buf)]
-			n, err := r.r.Read(r.buf)
-			if n == 0 && err != nil {
+			// Check the limits before we read more, so a hostile input can't make us use unlimited memory
+			if err := r.checkLimits(len(r.parsed) - start); err != nil {
+				return err
+			}
+			if err := r.fill(); err != nil {
 				if err == io.EOF {
 					r.fileDone = true
 					r.rowDone = true
-					if s == cellStateInQuote {
-						return io.ErrUnexpectedEOF
+					if (s == cellStateInQuote || s == cellStateInQuoteSlashR) && !r.lazyQuotes {
+						return r.parseError(io.ErrUnexpectedEOF, 0)
 					}
 					return nil
 				}
 				return err
 			}
-			r.buf = r.buf[:n]
-			r.pos = 0
 		}
 
 		buf := r.buf[r.pos:]
@@ -187,19 +916,29 @@ func (r *Reader) scanCell() error {
 			switch s {
 			case cellStateBegin:
 				switch c {
-				case '"':
+				case quote:
 					// This cell is a quoted string
 					s = cellStateInQuote
-				case ',':
+					r.cellQuoted = true
+				case delim:
 					// end of cell
 					return nil
 				case ' ', '\t':
-					// Skip initial white space
+					if !r.trimSpace {
+						r.parsed = append(r.parsed, c)
+						s = cellStateInCell
+						break
+					}
+					// Otherwise skip initial white space. The cell starts after it
+					p := &r.positions[len(r.positions)-1]
+					p.offset++
+					p.column++
 				case '\r':
 					s = cellStateSlashR
 				case '\n':
 					// end of cell & row
 					r.rowDone = true
+					r.newLine()
 					return nil
 				default:
 					r.parsed = append(r.parsed, c)
@@ -208,14 +947,20 @@ func (r *Reader) scanCell() error {
 
 			case cellStateInCell:
 				switch c {
-				case ',':
+				case delim:
 					// end of cell
 					return nil
+				case quote:
+					if !r.bareQuotes {
+						return r.parseError(ErrBareQuote, 1)
+					}
+					r.parsed = append(r.parsed, c)
 				case '\r':
 					s = cellStateSlashR
 				case '\n':
 					// end of cell & row
 					r.rowDone = true
+					r.newLine()
 					return nil
 				default:
 					r.parsed = append(r.parsed, c)
@@ -223,63 +968,146 @@ func (r *Reader) scanCell() error {
 
 			case cellStateInQuote:
 				switch c {
-				case '"':
+				case quote:
 					// Either end of cell, or a quoted quote
 					s = cellStateInQuoteQuote
+				case '\n':
+					r.newLine()
+					r.parsed = append(r.parsed, c)
+				case '\r':
+					if r.compat {
+						// "\r\n" within a quoted cell is read as "\n"
+						s = cellStateInQuoteSlashR
+						break
+					}
+					r.parsed = append(r.parsed, c)
 				default:
 					r.parsed = append(r.parsed, c)
 				}
 
+			case cellStateInQuoteSlashR:
+				switch c {
+				case '\n':
+					r.newLine()
+					r.parsed = append(r.parsed, c)
+					s = cellStateInQuote
+				case quote:
+					r.parsed = append(r.parsed, '\r')
+					s = cellStateInQuoteQuote
+				case '\r':
+					r.parsed = append(r.parsed, c)
+				default:
+					r.parsed = append(r.parsed, '\r', c)
+					s = cellStateInQuote
+				}
+
 			case cellStateInQuoteQuote:
 				switch c {
-				case '"':
+				case quote:
 					// This cell is a quoted string
 					r.parsed = append(r.parsed, c)
 					s = cellStateInQuote
-				case ',':
+				case delim:
 					// end of cell
 					return nil
-				case ' ', '\t':
-					s = cellStateTrailingWhiteSpace
 				case '\r':
+					if r.compat {
+						// Only a line ending may follow the closing quote
+						s = cellStateQuoteSlashR
+						break
+					}
 					s = cellStateSlashR
 				case '\n':
 					// end of cell & row
 					r.rowDone = true
+					r.newLine()
 					return nil
 				default:
-					return fmt.Errorf("unexpected char %c after terminating quote", c)
+					switch {
+					case r.lazyQuotes:
+						// The quote we saw was just part of the cell
+						r.parsed = append(r.parsed, quote, c)
+						s = cellStateInQuote
+					case r.strict:
+						return r.parseError(ErrQuote, 1)
+					case c == ' ' || c == '\t':
+						if r.keepSpace {
+							r.parsed = append(r.parsed, c)
+						}
+						s = cellStateTrailingWhiteSpace
+					default:
+						return r.parseError(fmt.Errorf("unexpected char %c after terminating quote", c), 1)
+					}
 				}
 
 			case cellStateTrailingWhiteSpace:
 				switch c {
-				case ',':
+				case delim:
 					// end of cell
 					return nil
 				case ' ', '\t':
-					// skip white space
+					if r.keepSpace {
+						r.parsed = append(r.parsed, c)
+					}
+					// Otherwise skip white space
 				case '\r':
 					s = cellStateSlashR
 				case '\n':
 					// end of cell & row
 					r.rowDone = true
+					r.newLine()
 					return nil
 				default:
-					return fmt.Errorf("unexpected char %c after quoted cell", c)
+					return r.parseError(fmt.Errorf("unexpected char %c after quoted cell", c), 1)
 				}
 
-			case cellStateSlashR:
-				switch c {
-				case ',':
-					r.parsed = append(r.parsed, '\r')
+			case cellStateQuoteSlashR:
+				switch {
+				case c == '\n':
+					// end of cell & row
+					r.rowDone = true
+					r.newLine()
 					return nil
+				case !r.lazyQuotes:
+					return r.parseError(ErrQuote, 2)
+				}
+				// The quote and '\r' we saw were just part of the cell
+				r.parsed = append(r.parsed, quote, '\r')
+				switch c {
+				case quote:
+					s = cellStateInQuoteQuote
 				case '\r':
-					r.parsed = append(r.parsed, '\r')
+					s = cellStateInQuoteSlashR
+				default:
+					r.parsed = append(r.parsed, c)
+					s = cellStateInQuote
+				}
+
+			case cellStateSlashR:
+				switch c {
 				case '\n':
 					// end of cell & row
 					r.rowDone = true
+					r.newLine()
 					return nil
+				case delim:
+					if r.strict && !r.compat {
+						return r.parseError(ErrBareCR, 2)
+					}
+					r.parsed = append(r.parsed, '\r')
+					return nil
+				case '\r':
+					if r.strict && !r.compat {
+						return r.parseError(ErrBareCR, 2)
+					}
+					r.parsed = append(r.parsed, '\r')
 				default:
+					if r.strict && !r.compat {
+						return r.parseError(ErrBareCR, 2)
+					}
+					if c == quote && !r.bareQuotes {
+						return r.parseError(ErrBareQuote, 1)
+					}
 					r.parsed = append(r.parsed, '\r', c)
 					s = cellStateInCell
 				}
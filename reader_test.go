@@ -2,13 +2,18 @@ package csv_test
 
 import (
 	"bytes"
+	"context"
 	csvstd "encoding/csv"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"slices"
 	"strconv"
 	"strings"
 	"testing"
+	"testing/iotest"
+	"time"
 
 	"github.com/philpearl/csv"
 	"github.com/stretchr/testify/assert"
@@ -101,7 +106,7 @@ func TestReadBool(t *testing.T) {
 	assert.True(t, b)
 
 	_, err = r.Bool(2)
-	assert.EqualError(t, err, "strconv.ParseBool: parsing \"cheese\": invalid syntax")
+	assert.EqualError(t, err, "record 1, cell 2 (line 1, offset 13): strconv.ParseBool: parsing \"cheese\": invalid syntax")
 
 	assert.Equal(t, 3, r.Len())
 }
@@ -122,7 +127,7 @@ func TestReadInt(t *testing.T) {
 	assert.Equal(t, 42, i)
 
 	_, err = r.Int(2)
-	assert.EqualError(t, err, "strconv.Atoi: parsing \"13.2\": invalid syntax")
+	assert.EqualError(t, err, "record 1, cell 2 (line 1, offset 7): strconv.Atoi: parsing \"13.2\": invalid syntax")
 }
 
 func TestReadFloat(t *testing.T) {
@@ -140,7 +145,7 @@ func TestReadFloat(t *testing.T) {
 	assert.Equal(t, 42.2, f)
 
 	_, err = r.Float(2)
-	assert.EqualError(t, err, "strconv.ParseFloat: parsing \"12h2\": invalid syntax")
+	assert.EqualError(t, err, "record 1, cell 2 (line 1, offset 9): strconv.ParseFloat: parsing \"12h2\": invalid syntax")
 }
 
 func TestReadRaw(t *testing.T) {
@@ -211,13 +216,13 @@ func TestRead(t *testing.T) {
 		{
 			name: "EOF in quote",
 			in:   `"`,
-			err:  "unexpected EOF",
+			err:  "record 1, cell 0 (line 1, offset 1): unexpected EOF",
 			exp:  [][]string(nil),
 		},
 		{
 			name: "quote after quote",
 			in:   `"a" "`,
-			err:  "unexpected char \" after quoted cell",
+			err:  "record 1, cell 0 (line 1, offset 4): unexpected char \" after quoted cell",
 			exp: [][]string{
 				{""},
 			},
@@ -225,7 +230,7 @@ func TestRead(t *testing.T) {
 		{
 			name: "char after quote",
 			in:   `"a"b`,
-			err:  "unexpected char b after terminating quote",
+			err:  "record 1, cell 0 (line 1, offset 3): unexpected char b after terminating quote",
 			exp: [][]string{
 				{""},
 			},
@@ -233,7 +238,7 @@ func TestRead(t *testing.T) {
 		{
 			name: "char after quote space",
 			in:   `"a" b`,
-			err:  "unexpected char b after quoted cell",
+			err:  "record 1, cell 0 (line 1, offset 4): unexpected char b after quoted cell",
 			exp: [][]string{
 				{""},
 			},
@@ -468,3 +473,866 @@ func BenchmarkReadOldWay(b *testing.B) {
 
 	assert.InEpsilon(b, float64(b.N)*12.3, total, 0.1)
 }
+
+func TestReadDialect(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect csv.Dialect
+		in      string
+		exp     [][]string
+	}{
+		{
+			name:    "tab",
+			dialect: csv.Dialect{Comma: '\t'},
+			in:      "a\tb c\t\"d\te\"\n1\t\t3",
+			exp: [][]string{
+				{"a", "b c", "d\te"},
+				{"1", "", "3"},
+			},
+		},
+		{
+			name:    "tab empty cells",
+			dialect: csv.Dialect{Comma: '\t'},
+			in:      "1\t\t3\n\t\"b\"\t\t\"d\"\n",
+			exp: [][]string{
+				{"1", "", "3"},
+				{"", "b", "", "d"},
+				{""},
+			},
+		},
+		{
+			name:    "space",
+			dialect: csv.Dialect{Comma: ' '},
+			in:      "a  b\n\t\"c\"  d\n",
+			exp: [][]string{
+				{"a", "", "b"},
+				{"c", "", "d"},
+				{""},
+			},
+		},
+		{
+			name:    "pipe",
+			dialect: csv.Dialect{Comma: '|'},
+			in:      "a|b,c| 'd'\n",
+			exp: [][]string{
+				{"a", "b,c", "'d'"},
+				{""},
+			},
+		},
+		{
+			name:    "semicolon single quote",
+			dialect: csv.Dialect{Comma: ';', Quote: '\''},
+			in:      "'a;b';'it''s';\"c\"",
+			exp: [][]string{
+				{"a;b", "it's", "\"c\""},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := csv.NewReader(strings.NewReader(test.in), test.dialect)
+
+			var actual [][]string
+			for {
+				ss, err := r.Read()
+				if err == io.EOF {
+					break
+				}
+				if !assert.NoError(t, err) {
+					return
+				}
+				actual = append(actual, append([]string(nil), ss...))
+			}
+			assert.Equal(t, test.exp, actual)
+		})
+	}
+}
+
+func TestReadInvalidDialect(t *testing.T) {
+	assert.Panics(t, func() { csv.NewReader(strings.NewReader(""), csv.Dialect{Comma: '"'}) })
+	assert.Panics(t, func() { csv.NewReader(strings.NewReader(""), csv.Dialect{Comma: '\n'}) })
+	// Bytes of multi-byte UTF-8 characters can't be used
+	assert.Panics(t, func() { csv.NewReader(strings.NewReader("é,b"), csv.Dialect{Comma: 0xA9}) })
+	assert.Panics(t, func() { csv.NewWriter(io.Discard, csv.Dialect{Quote: 0x80}) })
+}
+
+func TestReadStrictAndLazy(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		opts []csv.ReaderOption
+		exp  [][]string
+		err  error
+	}{
+		{
+			name: "strict valid",
+			in:   "a,\"b \"\"c\"\"\",\"d\r\ne\"\r\n f,g \n",
+			opts: []csv.ReaderOption{csv.Strict()},
+			exp:  [][]string{{"a", "b \"c\"", "d\r\ne"}, {" f", "g "}, {""}},
+		},
+		{
+			name: "stdlib compatible",
+			in:   "a\rb,\"c\r\nd\"\r\n\r\n\"e\r\"\n\n",
+			opts: []csv.ReaderOption{csv.StdlibCompatible()},
+			exp:  [][]string{{"a\rb", "c\nd"}, {"e\r"}},
+		},
+		{
+			name: "stdlib compatible CR after quote",
+			in:   "\"a\"\rb",
+			opts: []csv.ReaderOption{csv.StdlibCompatible()},
+			err:  csv.ErrQuote,
+		},
+		{
+			name: "stdlib compatible lazy CR after quote",
+			in:   "\"a\"\rb\"\r",
+			opts: []csv.ReaderOption{csv.StdlibCompatible(), csv.LazyQuotes()},
+			exp:  [][]string{{"a\"\rb"}},
+		},
+		{
+			name: "stdlib compatible bare quote after CR",
+			in:   "a\r\"",
+			opts: []csv.ReaderOption{csv.StdlibCompatible()},
+			err:  csv.ErrBareQuote,
+		},
+		{
+			name: "strict bare quote",
+			in:   "a,b\"c",
+			opts: []csv.ReaderOption{csv.Strict()},
+			err:  csv.ErrBareQuote,
+		},
+		{
+			name: "strict quote after space",
+			in:   "a, \"b\"",
+			opts: []csv.ReaderOption{csv.Strict()},
+			err:  csv.ErrBareQuote,
+		},
+		{
+			name: "strict trim leading space",
+			in:   "a, \"b\"",
+			opts: []csv.ReaderOption{csv.Strict(), csv.TrimLeadingSpace(true)},
+			exp:  [][]string{{"a", "b"}},
+		},
+		{
+			name: "strict space after quote",
+			in:   "\"a\" ,b",
+			opts: []csv.ReaderOption{csv.Strict()},
+			err:  csv.ErrQuote,
+		},
+		{
+			name: "strict char after quote",
+			in:   "\"a\"b",
+			opts: []csv.ReaderOption{csv.Strict()},
+			err:  csv.ErrQuote,
+		},
+		{
+			name: "strict bare CR",
+			in:   "a\rb",
+			opts: []csv.ReaderOption{csv.Strict()},
+			err:  csv.ErrBareCR,
+		},
+		{
+			name: "strict bare CR before comma",
+			in:   "a\r,b",
+			opts: []csv.ReaderOption{csv.Strict()},
+			err:  csv.ErrBareCR,
+		},
+		{
+			name: "strict EOF in quote",
+			in:   "\"a",
+			opts: []csv.ReaderOption{csv.Strict()},
+			err:  io.ErrUnexpectedEOF,
+		},
+		{
+			name: "strict lazy quotes",
+			in:   "a\"b,\"c\"d\",\"e\" \n",
+			opts: []csv.ReaderOption{csv.Strict(), csv.LazyQuotes()},
+			exp:  [][]string{{"a\"b", "c\"d", "e\" \n"}},
+		},
+		{
+			name: "lazy quotes",
+			in:   "a\"b, \"c\"d\",\"e",
+			opts: []csv.ReaderOption{csv.LazyQuotes()},
+			exp:  [][]string{{"a\"b", "c\"d", "e"}},
+		},
+		{
+			name: "no trim",
+			in:   " a,\t\"b\"",
+			opts: []csv.ReaderOption{csv.TrimLeadingSpace(false)},
+			exp:  [][]string{{" a", "\t\"b\""}},
+		},
+		{
+			name: "keep whitespace",
+			in:   "  007 , \"b\" ,\"c\" \t,\t\n\"d\"  ",
+			opts: []csv.ReaderOption{csv.KeepWhitespace()},
+			exp:  [][]string{{"  007 ", " \"b\" ", "c \t", "\t"}, {"d  "}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := csv.NewReader(strings.NewReader(test.in), test.opts...)
+
+			var actual [][]string
+			for {
+				ss, err := r.Read()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					assert.True(t, errors.Is(err, test.err), "error is %v", err)
+					return
+				}
+				actual = append(actual, append([]string(nil), ss...))
+			}
+			assert.NoError(t, test.err)
+			assert.Equal(t, test.exp, actual)
+		})
+	}
+}
+
+func TestReadSkipLines(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    string
+		opts  []csv.ReaderOption
+		exp   [][]string
+		lines []int
+	}{
+		{
+			name: "blank lines kept by default",
+			in:   "a\n\nb\n",
+			exp:  [][]string{{"a"}, {""}, {"b"}, {""}},
+		},
+		{
+			name:  "blank lines",
+			in:    "\n\r\na\n\n \n\r\nb\n\n",
+			opts:  []csv.ReaderOption{csv.SkipBlankLines()},
+			exp:   [][]string{{"a"}, {""}, {"b"}},
+			lines: []int{3, 5, 7},
+		},
+		{
+			name: "empty",
+			in:   "",
+			opts: []csv.ReaderOption{csv.SkipBlankLines()},
+		},
+		{
+			name:  "comments",
+			in:    "# header\na,b\n#\n #c\n\"#d\"\n#e",
+			opts:  []csv.ReaderOption{csv.Comment('#')},
+			exp:   [][]string{{"a", "b"}, {"#c"}, {"#d"}},
+			lines: []int{2, 4, 5},
+		},
+		{
+			name:  "comments and blank lines",
+			in:    "a\n\n;comment\r\n\n;comment,\"\nb\n",
+			opts:  []csv.ReaderOption{csv.Comment(';'), csv.SkipBlankLines()},
+			exp:   [][]string{{"a"}, {"b"}},
+			lines: []int{1, 6},
+		},
+		{
+			name: "comments leave trailing empty row",
+			in:   "a\n#comment\n",
+			opts: []csv.ReaderOption{csv.Comment('#')},
+			exp:  [][]string{{"a"}},
+		},
+		{
+			name: "comment in quoted cell",
+			in:   "\"a\n#b\"\n",
+			opts: []csv.ReaderOption{csv.Comment('#'), csv.SkipBlankLines()},
+			exp:  [][]string{{"a\n#b"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Reading a byte at a time checks we handle buffer refills
+			r := csv.NewReader(iotest.OneByteReader(strings.NewReader(test.in)), test.opts...)
+
+			var actual [][]string
+			var lines []int
+			for {
+				ss, err := r.Read()
+				if err == io.EOF {
+					break
+				}
+				if !assert.NoError(t, err) {
+					return
+				}
+				actual = append(actual, append([]string(nil), ss...))
+
+				// Use a conversion error to find the line number of the record
+				_, err = r.Int(0)
+				var pe *csv.ParseError
+				if errors.As(err, &pe) {
+					lines = append(lines, pe.Line)
+				}
+			}
+			assert.Equal(t, test.exp, actual)
+			if test.lines != nil {
+				assert.Equal(t, test.lines, lines)
+			}
+		})
+	}
+}
+
+func TestReadInvalidComment(t *testing.T) {
+	assert.Panics(t, func() { csv.NewReader(strings.NewReader(""), csv.Comment(',')) })
+	assert.Panics(t, func() { csv.NewReader(strings.NewReader(""), csv.Comment('\n')) })
+}
+
+func TestReadSizedInts(t *testing.T) {
+	r := csv.NewReader(strings.NewReader("127,-129,32767,-2147483649,9223372036854775807,255,256,65535,4294967296,18446744073709551615,-1"))
+	assert.NoError(t, r.Scan())
+
+	i8, err := r.Int8(0)
+	assert.NoError(t, err)
+	assert.Equal(t, int8(127), i8)
+	_, err = r.Int8(1)
+	assert.True(t, errors.Is(err, strconv.ErrRange))
+
+	i16, err := r.Int16(2)
+	assert.NoError(t, err)
+	assert.Equal(t, int16(32767), i16)
+	i32, err := r.Int32(1)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(-129), i32)
+	_, err = r.Int32(3)
+	assert.EqualError(t, err, `record 1, cell 3 (line 1, offset 15): strconv.ParseInt: parsing "-2147483649": value out of range`)
+	i64, err := r.Int64(4)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(9223372036854775807), i64)
+
+	u8, err := r.Uint8(5)
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(255), u8)
+	_, err = r.Uint8(6)
+	assert.True(t, errors.Is(err, strconv.ErrRange))
+	u16, err := r.Uint16(7)
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(65535), u16)
+	_, err = r.Uint32(8)
+	assert.True(t, errors.Is(err, strconv.ErrRange))
+	u64, err := r.Uint64(9)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(18446744073709551615), u64)
+	_, err = r.Uint64(10)
+	assert.True(t, errors.Is(err, strconv.ErrSyntax))
+}
+
+func TestReadFloat32(t *testing.T) {
+	r := csv.NewReader(strings.NewReader("0.1,1e39,x"))
+	assert.NoError(t, r.Scan())
+
+	f, err := r.Float32(0)
+	assert.NoError(t, err)
+	assert.Equal(t, float32(0.1), f)
+	_, err = r.Float32(1)
+	assert.True(t, errors.Is(err, strconv.ErrRange))
+	_, err = r.Float32(2)
+	assert.True(t, errors.Is(err, strconv.ErrSyntax))
+}
+
+func TestRoundTripSized(t *testing.T) {
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	w.Int32(-2147483648)
+	w.Uint64(18446744073709551615)
+	w.Float32(0.1)
+	w.Float32(3.4028235e38)
+	assert.NoError(t, w.LineComplete())
+	assert.Equal(t, "-2147483648,18446744073709551615,0.1,3.4028235e+38\n", b.String())
+
+	r := csv.NewReader(&b)
+	assert.NoError(t, r.Scan())
+	i, err := r.Int32(0)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(-2147483648), i)
+	u, err := r.Uint64(1)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(18446744073709551615), u)
+	f, err := r.Float32(2)
+	assert.NoError(t, err)
+	assert.Equal(t, float32(0.1), f)
+	f, err = r.Float32(3)
+	assert.NoError(t, err)
+	assert.Equal(t, float32(3.4028235e38), f)
+}
+
+func TestReadPositions(t *testing.T) {
+	type cellPos struct {
+		Offset       int64
+		Line, Column int
+	}
+	in := "a, b,\"c\r\nd\"\r\n#x\n\ne,\"f\"\n"
+	// Reading a byte at a time checks we keep track of positions across buffer refills
+	r := csv.NewReader(iotest.OneByteReader(strings.NewReader(in)), csv.Comment('#'), csv.SkipBlankLines())
+
+	cells := func() []cellPos {
+		var pos []cellPos
+		for i := range r.Len() {
+			line, col := r.FieldPos(i)
+			pos = append(pos, cellPos{Offset: r.CellOffset(i), Line: line, Column: col})
+		}
+		return pos
+	}
+
+	assert.NoError(t, r.Scan())
+	assert.Equal(t, int64(0), r.RecordOffset())
+	assert.Equal(t, []cellPos{{0, 1, 1}, {3, 1, 4}, {5, 1, 6}}, cells())
+	assert.Equal(t, int64(13), r.InputOffset())
+	_, err := r.Int(2)
+	assert.EqualError(t, err, "record 1, cell 2 (line 1, offset 5): strconv.Atoi: parsing \"c\\r\\nd\": invalid syntax")
+
+	assert.NoError(t, r.Scan())
+	assert.Equal(t, int64(17), r.RecordOffset())
+	assert.Equal(t, []cellPos{{17, 5, 1}, {19, 5, 3}}, cells())
+	assert.Equal(t, int64(23), r.InputOffset())
+	_, err = r.Int(1)
+	var pe *csv.ParseError
+	if assert.True(t, errors.As(err, &pe)) {
+		assert.Equal(t, csv.ParseError{Line: 5, Col: 3, StartLine: 5, Record: 2, Cell: 1, Offset: 19, Err: pe.Err}, *pe)
+	}
+
+	assert.Equal(t, io.EOF, r.Scan())
+}
+
+func TestReadFieldsPerRecord(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		opts []csv.ReaderOption
+		exp  [][]string
+		errs []string
+	}{
+		{
+			name: "fixed",
+			in:   "a,b\nc\nd,e,f\n\ng,h\n",
+			opts: []csv.ReaderOption{csv.FieldsPerRecord(2)},
+			exp:  [][]string{{"a", "b"}, {"c"}, {"d", "e", "f"}, {""}, {"g", "h"}, {""}},
+			errs: []string{
+				"",
+				"record 2, cell 1 (line 2, offset 4): wrong number of fields: have 1, want 2",
+				"record 3, cell 2 (line 3, offset 6): wrong number of fields: have 3, want 2",
+				"record 4, cell 1 (line 4, offset 12): wrong number of fields: have 1, want 2",
+				"",
+				// The empty record after the final line ending is not checked
+				"",
+			},
+		},
+		{
+			name: "blank line",
+			in:   "a,b\n\nc,d\n",
+			opts: []csv.ReaderOption{csv.FieldsPerRecord(0)},
+			exp:  [][]string{{"a", "b"}, {""}, {"c", "d"}, {""}},
+			errs: []string{"", "record 2, cell 1 (line 2, offset 4): wrong number of fields: have 1, want 2", "", ""},
+		},
+		{
+			name: "skip blank lines",
+			in:   "a,b\n\nc,d\n",
+			opts: []csv.ReaderOption{csv.FieldsPerRecord(2), csv.SkipBlankLines()},
+			exp:  [][]string{{"a", "b"}, {"c", "d"}},
+			errs: []string{"", ""},
+		},
+		{
+			name: "from first record",
+			in:   "a,b,c\nd,e\nf,g,h",
+			opts: []csv.ReaderOption{csv.FieldsPerRecord(0)},
+			exp:  [][]string{{"a", "b", "c"}, {"d", "e"}, {"f", "g", "h"}},
+			errs: []string{"", "record 2, cell 2 (line 2, offset 6): wrong number of fields: have 2, want 3", ""},
+		},
+		{
+			name: "from header",
+			in:   "x,y\n1,2\n3",
+			opts: []csv.ReaderOption{csv.FieldsPerRecord(0), csv.UseHeader()},
+			exp:  [][]string{{"1", "2"}, {"3"}},
+			errs: []string{"", "record 3, cell 1 \"y\" (line 3, offset 8): wrong number of fields: have 1, want 2"},
+		},
+		{
+			name: "no check",
+			in:   "a,b\nc\n",
+			opts: []csv.ReaderOption{csv.FieldsPerRecord(-1)},
+			exp:  [][]string{{"a", "b"}, {"c"}, {""}},
+			errs: []string{"", "", ""},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := csv.NewReader(strings.NewReader(test.in), test.opts...)
+
+			var actual [][]string
+			var errs []string
+			for {
+				err := r.Scan()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					assert.True(t, errors.Is(err, csv.ErrFieldCount))
+					errs = append(errs, err.Error())
+				} else {
+					errs = append(errs, "")
+				}
+				// The record is available even if it has the wrong number of fields
+				var row []string
+				for i := range r.Len() {
+					row = append(row, r.Text(i))
+				}
+				actual = append(actual, row)
+			}
+			assert.Equal(t, test.exp, actual)
+			assert.Equal(t, test.errs, errs)
+		})
+	}
+}
+
+func TestReadFieldsPerRecordHeader(t *testing.T) {
+	r := csv.NewReader(strings.NewReader("x,y\n1,2,3\n"), csv.FieldsPerRecord(3), csv.UseHeader())
+	err := r.Scan()
+	assert.EqualError(t, err, "record 1, cell 2 (line 1, offset 0): wrong number of fields: have 2, want 3")
+
+	// The header is still used
+	assert.NoError(t, r.Scan())
+	v, err := r.IntByName("y")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, v)
+}
+
+func TestReadNoCell(t *testing.T) {
+	r := csv.NewReader(strings.NewReader("a,1\nb"))
+	assert.NoError(t, r.Scan())
+	assert.NoError(t, r.Scan())
+
+	checks := map[string]func() error{
+		"Int":        func() error { _, err := r.Int(1); return err },
+		"Float":      func() error { _, err := r.Float(1); return err },
+		"Bool":       func() error { _, err := r.Bool(1); return err },
+		"Int64":      func() error { _, err := r.Int64(1); return err },
+		"Uint8":      func() error { _, err := r.Uint8(1); return err },
+		"Float32":    func() error { _, err := r.Float32(1); return err },
+		"Time":       func() error { _, err := r.Time(1, time.RFC3339); return err },
+		"Duration":   func() error { _, err := r.Duration(1); return err },
+		"NullString": func() error { _, err := r.NullString(1); return err },
+		"NullBool":   func() error { _, err := r.NullBool(1); return err },
+		"NullTime":   func() error { _, err := r.NullTime(1, time.RFC3339); return err },
+		"Cell":       func() error { _, err := r.Cell(1); return err },
+		"CellText":   func() error { _, err := r.CellText(1); return err },
+		"negative":   func() error { _, err := r.Cell(-1); return err },
+	}
+	for name, check := range checks {
+		t.Run(name, func(t *testing.T) {
+			err := check()
+			assert.True(t, errors.Is(err, csv.ErrNoCell), "error is %v", err)
+		})
+	}
+	_, err := r.Int(1)
+	assert.EqualError(t, err, "record 2, cell 1 (line 2, offset 4): no such cell in row")
+
+	c, err := r.Cell(0)
+	assert.NoError(t, err)
+	assert.Equal(t, "b", string(c))
+	s, err := r.CellText(0)
+	assert.NoError(t, err)
+	assert.Equal(t, "b", s)
+}
+
+func FuzzReadBuffering(f *testing.F) {
+	for _, in := range testInputs {
+		f.Add(in)
+	}
+	f.Add("a, \"b\" ,\"c\"\"\"\r\n\"d\r\ne\"\rf,\" g\"\"\" \n")
+	f.Fuzz(func(t *testing.T, in string) {
+		for _, config := range testConfigs {
+			// Cells are usually left in the read buffer. Reading a byte at a time means every row is split
+			// across reads, so every row is copied instead.
+			expRows, expErr := readSequential(strings.NewReader(in), config.opts...)
+			rows, err := readSequential(iotest.OneByteReader(strings.NewReader(in)), config.opts...)
+			assert.Equal(t, expRows, rows, config.name)
+			assert.Equal(t, expErr, err, config.name)
+		}
+	})
+}
+
+func TestReadLimits(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		opts []csv.ReaderOption
+		exp  [][]string
+		err  string
+	}{
+		{
+			name: "within limits",
+			in:   "abc,\"d\"\"e\"\n,,\n",
+			opts: []csv.ReaderOption{csv.MaxCellSize(3), csv.MaxRowSize(11), csv.MaxCellsPerRow(3)},
+			exp:  [][]string{{"abc", "d\"e"}, {"", "", ""}, {""}},
+		},
+		{
+			name: "cell too large",
+			in:   "abc,defg\n",
+			opts: []csv.ReaderOption{csv.MaxCellSize(3)},
+			err:  `record 1, cell 1 (line 1, offset 4): cell too large (limit 3)`,
+		},
+		{
+			name: "quoted cell too large",
+			in:   "a\n\"b\"\"\"\"c\"\n",
+			opts: []csv.ReaderOption{csv.MaxCellSize(3)},
+			exp:  [][]string{{"a"}},
+			err:  `record 2, cell 0 (line 2, offset 2): cell too large (limit 3)`,
+		},
+		{
+			name: "row too large",
+			in:   "a,b\nc,d,e\n",
+			opts: []csv.ReaderOption{csv.MaxRowSize(4)},
+			exp:  [][]string{{"a", "b"}},
+			err:  `record 2, cell 2 (line 2, offset 4): row too large (limit 4)`,
+		},
+		{
+			name: "too many cells",
+			in:   "a,b\nc,d,e\n",
+			opts: []csv.ReaderOption{csv.MaxCellsPerRow(2)},
+			exp:  [][]string{{"a", "b"}},
+			err:  `record 2, cell 2 (line 2, offset 8): too many cells in row (limit 2)`,
+		},
+	}
+
+	for _, test := range tests {
+		for _, oneByte := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/%t", test.name, oneByte), func(t *testing.T) {
+				var in io.Reader = strings.NewReader(test.in)
+				if oneByte {
+					in = iotest.OneByteReader(in)
+				}
+				r := csv.NewReader(in, test.opts...)
+				var rows [][]string
+				for record, err := range r.Records() {
+					if err != nil {
+						assert.EqualError(t, err, test.err)
+						break
+					}
+					rows = append(rows, slices.Clone(record))
+				}
+				assert.Equal(t, test.exp, rows)
+			})
+		}
+	}
+}
+
+func TestReadLimitsEndlessInput(t *testing.T) {
+	tests := []struct {
+		name string
+		in   io.Reader
+		opt  csv.ReaderOption
+		err  error
+	}{
+		{
+			name: "unterminated quote",
+			in:   io.MultiReader(strings.NewReader(`"`), &repeatReader{content: []byte("a,\n")}),
+			opt:  csv.MaxCellSize(1000),
+			err:  csv.ErrCellTooLarge,
+		},
+		{
+			name: "endless cell",
+			in:   &repeatReader{content: []byte("a")},
+			opt:  csv.MaxRowSize(1000),
+			err:  csv.ErrRowTooLarge,
+		},
+		{
+			name: "endless row",
+			in:   &repeatReader{content: []byte(",")},
+			opt:  csv.MaxCellsPerRow(1000),
+			err:  csv.ErrTooManyCells,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := csv.NewReader(test.in, test.opt)
+			err := r.Scan()
+			assert.True(t, errors.Is(err, test.err), "error is %v", err)
+			// We stop soon after the limit is reached
+			assert.True(t, r.InputOffset() < 10000, "read %d bytes", r.InputOffset())
+		})
+	}
+}
+
+func BenchmarkReadQuoted(b *testing.B) {
+	// The doubled quotes mean the cells must be copied
+	content := []byte(`"cheese", "feet", "le""mon", 99, 1002, 1298, 12.3, 17, 11, "wh""ale"
+`)
+	r := csv.NewReader(&repeatReader{content: content})
+
+	b.SetBytes(int64(len(content)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err := r.Scan(); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := r.Float(6); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// slowReader returns a byte at a time from r, waiting before each one
+type slowReader struct {
+	r     io.Reader
+	delay time.Duration
+}
+
+func (s *slowReader) Read(p []byte) (int, error) {
+	time.Sleep(s.delay)
+	return s.r.Read(p[:1])
+}
+
+func TestScanContext(t *testing.T) {
+	r := csv.NewReader(&slowReader{r: strings.NewReader("a,b\nc,d"), delay: time.Microsecond})
+	ctx := context.Background()
+	var rows [][]string
+	for {
+		err := r.ScanContext(ctx)
+		if err == io.EOF {
+			break
+		}
+		if !assert.NoError(t, err) {
+			return
+		}
+		rows = append(rows, []string{r.Text(0), r.Text(1)})
+	}
+	assert.Equal(t, [][]string{{"a", "b"}, {"c", "d"}}, rows)
+}
+
+func TestScanContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r := csv.NewReader(strings.NewReader("a,b\n"))
+	assert.Equal(t, context.Canceled, r.ScanContext(ctx))
+}
+
+func TestScanContextTimeout(t *testing.T) {
+	// This row never ends, so Scan would never return
+	r := csv.NewReader(&slowReader{r: &repeatReader{content: []byte("a")}, delay: time.Millisecond})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	assert.Equal(t, context.DeadlineExceeded, r.ScanContext(ctx))
+	assert.True(t, time.Since(start) < time.Second, "took %s", time.Since(start))
+}
+
+func TestRawRecord(t *testing.T) {
+	inputs := append(slices.Clone(testInputs), "a,\""+strings.Repeat("b\r\n", 5000)+"\"\r\nc,d\r\n")
+	for _, config := range testConfigs {
+		for i, in := range inputs {
+			for _, oneByte := range []bool{false, true} {
+				t.Run(fmt.Sprintf("%s/%d/%t", config.name, i, oneByte), func(t *testing.T) {
+					var rd io.Reader = strings.NewReader(in)
+					if oneByte {
+						rd = iotest.OneByteReader(rd)
+					}
+					r := csv.NewReader(rd, append(config.opts, csv.RetainRaw())...)
+					for {
+						if err := r.Scan(); err != nil {
+							if err == io.EOF {
+								break
+							}
+							if !errors.Is(err, csv.ErrFieldCount) {
+								return
+							}
+						}
+						assert.Equal(t, in[r.RecordOffset():r.InputOffset()], string(r.RawRecord()))
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestRawRecordCases(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		opts []csv.ReaderOption
+		exp  []string
+	}{
+		{
+			name: "line endings",
+			in:   "a,b\r\nc,d\ne,f",
+			exp:  []string{"a,b\r\n", "c,d\n", "e,f"},
+		},
+		{
+			name: "quoted",
+			in:   "\"a\nb\",\"c\"\"\"\n d , \"e\" \n",
+			opts: []csv.ReaderOption{csv.SkipBlankLines()},
+			exp:  []string{"\"a\nb\",\"c\"\"\"\n", " d , \"e\" \n"},
+		},
+		{
+			name: "skipped lines",
+			in:   "#x\n\na\n\n#y\nb\n",
+			opts: []csv.ReaderOption{csv.Comment('#'), csv.SkipBlankLines()},
+			exp:  []string{"a\n", "b\n"},
+		},
+		{
+			name: "byte order mark",
+			in:   "\ufeffa\nb\n",
+			opts: []csv.ReaderOption{csv.SkipBlankLines()},
+			exp:  []string{"a\n", "b\n"},
+		},
+		{
+			name: "header",
+			in:   "h\na\n",
+			opts: []csv.ReaderOption{csv.UseHeader(), csv.SkipBlankLines()},
+			exp:  []string{"a\n"},
+		},
+		{
+			name: "dialect",
+			in:   "'a;b';c\n",
+			opts: []csv.ReaderOption{csv.Dialect{Comma: ';', Quote: '\''}, csv.SkipBlankLines()},
+			exp:  []string{"'a;b';c\n"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := csv.NewReader(strings.NewReader(test.in), append(test.opts, csv.RetainRaw())...)
+			var raw []string
+			for row, err := range r.Rows() {
+				if !assert.NoError(t, err) {
+					return
+				}
+				raw = append(raw, string(row.RawRecord()))
+			}
+			assert.Equal(t, test.exp, raw)
+		})
+	}
+}
+
+func TestRawRecordNotRetained(t *testing.T) {
+	r := csv.NewReader(strings.NewReader("a,b\n"))
+	assert.NoError(t, r.Scan())
+	assert.Nil(t, r.RawRecord())
+}
+
+func TestRawRecordPassThrough(t *testing.T) {
+	// Records are passed through exactly as they are, unless they are changed
+	in := "id,name\r\n1,\"Smith, J\"\r\n2, \"Jones\"\"\"\n3,\"Brown\nand Co\"\r\n4,x"
+	var out bytes.Buffer
+	w := csv.NewWriter(&out, csv.Dialect{LineTerminator: "\r\n"})
+	r := csv.NewReader(strings.NewReader(in), csv.RetainRaw())
+	for row, err := range r.Rows() {
+		if !assert.NoError(t, err) {
+			return
+		}
+		if row.Text(0) == "2" {
+			w.String(row.Text(0))
+			w.String(strings.ToUpper(row.Text(1)))
+			assert.NoError(t, w.LineComplete())
+			continue
+		}
+		assert.NoError(t, w.RawLine(row.RawRecord()))
+	}
+	assert.Equal(t, "id,name\r\n1,\"Smith, J\"\r\n2,\"JONES\"\"\"\r\n3,\"Brown\nand Co\"\r\n4,x\r\n", out.String())
+}
@@ -0,0 +1,208 @@
+// Package stdcsv provides a Reader and Writer with the same API as those in encoding/csv, built on the faster
+// Reader and Writer in github.com/philpearl/csv. It lets you switch existing code from encoding/csv by
+// changing the import path.
+//
+// The Reader follows the parsing rules of encoding/csv, with a few exceptions.
+//   - Comma and Comment must be ASCII characters.
+//   - TrimLeadingSpace only discards spaces and tabs, rather than any Unicode white space.
+//   - If Read returns a ParseError it does not return the partial record, and if FieldsPerRecord is 0 the
+//     partial record does not set it.
+//   - The position reported for a quoted field that is not terminated before the end of the input can
+//     differ.
+//   - Comma, Comment, LazyQuotes and TrimLeadingSpace are read by the first call to Read, and changes to them
+//     after that are ignored. Changes to FieldsPerRecord and ReuseRecord take effect as they do in
+//     encoding/csv.
+//
+// The Writer matches encoding/csv.
+//
+// Errors are those of encoding/csv, so they can be checked with errors.Is and errors.As as before.
+package stdcsv
+
+import (
+	stdlib "encoding/csv"
+	"errors"
+	"io"
+	"unicode/utf8"
+
+	"github.com/philpearl/csv"
+)
+
+// A ParseError is returned for parsing errors. It is the ParseError from encoding/csv.
+type ParseError = stdlib.ParseError
+
+// These are the errors that can be returned in ParseError.Err. They are the errors from encoding/csv.
+var (
+	ErrBareQuote  = stdlib.ErrBareQuote
+	ErrQuote      = stdlib.ErrQuote
+	ErrFieldCount = stdlib.ErrFieldCount
+
+	// Deprecated: ErrTrailingComma is no longer used.
+	ErrTrailingComma = stdlib.ErrTrailingComma
+)
+
+var (
+	errInvalidDelim = errors.New("csv: invalid field or comment delimiter")
+	errNonASCII     = errors.New("csv: non-ASCII field or comment delimiter not supported")
+)
+
+// A Reader reads records from a CSV-encoded file. See encoding/csv for details. Apart from FieldsPerRecord
+// and ReuseRecord, the fields must be set before the first call to Read.
+type Reader struct {
+	// Comma is the field delimiter. It is set to ',' by NewReader.
+	Comma rune
+
+	// Comment, if not 0, is the comment character. Lines beginning with the Comment character without
+	// preceding whitespace are ignored.
+	Comment rune
+
+	// FieldsPerRecord is the number of expected fields per record. If FieldsPerRecord is positive, Read
+	// requires each record to have the given number of fields. If FieldsPerRecord is 0, Read sets it to the
+	// number of fields in the first record. If FieldsPerRecord is negative, no check is made.
+	FieldsPerRecord int
+
+	// If LazyQuotes is true, a quote may appear in an unquoted field and a non-doubled quote may appear in a
+	// quoted field.
+	LazyQuotes bool
+
+	// If TrimLeadingSpace is true, leading white space in a field is ignored.
+	TrimLeadingSpace bool
+
+	// ReuseRecord controls whether calls to Read may return a slice sharing the backing array of the previous
+	// call's returned slice for performance.
+	ReuseRecord bool
+
+	in     io.Reader
+	r      *csv.Reader
+	record []string
+}
+
+// NewReader returns a new Reader that reads from r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{
+		Comma: ',',
+		in:    r,
+	}
+}
+
+// Read reads one record (a slice of fields) from r. If there is no data left to be read, Read returns nil,
+// io.EOF. If ReuseRecord is true, the returned slice may be shared between multiple calls to Read.
+func (r *Reader) Read() (record []string, err error) {
+	if r.ReuseRecord {
+		record, err = r.readRecord(r.record[:0])
+		r.record = record
+		return record, err
+	}
+	return r.readRecord(nil)
+}
+
+// ReadAll reads all the remaining records from r. A successful call returns err == nil, not err == io.EOF.
+func (r *Reader) ReadAll() (records [][]string, err error) {
+	for {
+		record, err := r.readRecord(nil)
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+}
+
+// FieldPos returns the line and column corresponding to the start of the field with the given index in the
+// slice most recently returned by Read. Numbering of lines and columns starts at 1; columns are counted in
+// bytes. If this is called with an out-of-bounds index, it panics.
+func (r *Reader) FieldPos(field int) (line, column int) {
+	if r.r == nil || field < 0 || field >= r.r.Len() {
+		panic("out of range index passed to FieldPos")
+	}
+	return r.r.FieldPos(field)
+}
+
+// InputOffset returns the input stream byte offset of the current reader position. The offset gives the
+// location of the end of the most recently read row and the beginning of the next row.
+func (r *Reader) InputOffset() int64 {
+	if r.r == nil {
+		return 0
+	}
+	return r.r.InputOffset()
+}
+
+func (r *Reader) readRecord(dst []string) ([]string, error) {
+	if r.r == nil {
+		if err := r.init(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := r.r.Scan(); err != nil {
+		return nil, convertError(err)
+	}
+	for i := range r.r.Len() {
+		dst = append(dst, r.r.Text(i))
+	}
+
+	// We check the number of fields here rather than in the underlying Reader, as encoding/csv lets
+	// FieldsPerRecord change between calls to Read. The record is returned along with ErrFieldCount
+	switch {
+	case r.FieldsPerRecord == 0:
+		r.FieldsPerRecord = len(dst)
+	case r.FieldsPerRecord > 0 && len(dst) != r.FieldsPerRecord:
+		line, _ := r.r.FieldPos(0)
+		return dst, &ParseError{StartLine: line, Line: line, Column: 1, Err: ErrFieldCount}
+	}
+	return dst, nil
+}
+
+// init creates the underlying Reader using the settings in r
+func (r *Reader) init() error {
+	if !validDelim(r.Comma) || r.Comma == r.Comment || (r.Comment != 0 && !validDelim(r.Comment)) {
+		return errInvalidDelim
+	}
+	if r.Comma >= utf8.RuneSelf || r.Comment >= utf8.RuneSelf {
+		return errNonASCII
+	}
+
+	opts := []csv.ReaderOption{
+		csv.Dialect{Comma: byte(r.Comma)},
+		csv.StdlibCompatible(),
+		csv.Comment(byte(r.Comment)),
+		// After an error encoding/csv carries on from the next line. Returning the error from here leaves us
+		// there too
+		csv.OnBadRecord(func(bad csv.BadRecord) error { return bad.Err }),
+	}
+	if r.LazyQuotes {
+		opts = append(opts, csv.LazyQuotes())
+	}
+	if r.TrimLeadingSpace {
+		opts = append(opts, csv.TrimLeadingSpace(true))
+	}
+	r.r = csv.NewReader(r.in, opts...)
+	return nil
+}
+
+// convertError converts an error from the underlying Reader to the equivalent encoding/csv error
+func convertError(err error) error {
+	var pe *csv.ParseError
+	if !errors.As(err, &pe) {
+		return err
+	}
+
+	converted := &ParseError{StartLine: pe.StartLine, Line: pe.Line, Column: pe.Col, Err: pe.Err}
+	switch {
+	case pe.Err == csv.ErrBareQuote:
+		converted.Err = ErrBareQuote
+	case pe.Err == csv.ErrQuote:
+		// We report the character after the closing quote, encoding/csv reports the quote
+		converted.Err = ErrQuote
+		converted.Column--
+	case pe.Err == io.ErrUnexpectedEOF:
+		// An unterminated quoted field
+		converted.Err = ErrQuote
+	}
+	return converted
+}
+
+func validDelim(r rune) bool {
+	return r != 0 && r != '"' && r != '\r' && r != '\n' && utf8.ValidRune(r) && r != utf8.RuneError
+}
@@ -0,0 +1,360 @@
+package stdcsv_test
+
+import (
+	"bytes"
+	stdlib "encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/philpearl/csv/stdcsv"
+	"github.com/stretchr/testify/assert"
+)
+
+// readInputs are shared by the tests that compare our Reader with encoding/csv
+var readInputs = []string{
+	"",
+	"\n",
+	"a,b,c\n",
+	"a,b,c",
+	"a,b,c\n1,2,3\n",
+	"a,b\r\nc,d\r\n",
+	"a,b\r\n\r\nc,d",
+	"\n\na,b\n\n\nc,d\n\n",
+	"a\n\r",
+	"a,b\r",
+	"a\rb,c\r,d\r\r\n",
+	"a,,c\n,,\n",
+	" a , b ,c \n",
+	"\t\"a\"\n",
+	`"a","b,c","d""e"` + "\n",
+	`"a` + "\n" + `b","c` + "\r\n" + `d"` + "\n",
+	`"a` + "\r" + `b"` + "\n",
+	`"a"` + "\r" + "\n",
+	`"a"` + "\r",
+	`"a"` + "\rb,c\n",
+	`"a"` + "\r,c\n",
+	`"a""` + "\r\"\n",
+	`"a"b,c` + "\n",
+	`"a" ,c` + "\n",
+	`a"b,c` + "\n",
+	`a,"b`,
+	`"a""b"c"d"` + "\n",
+	`"a" "b",c` + "\n",
+	`a,b` + "\n" + `c,"d"x` + "\ne,f\n",
+	`a,b` + "\n" + `"c` + "\n" + `d"x,e` + "\nf,g\n",
+	"#comment\na,b\n#another\nc,#d\n",
+	"a;b;c\n;d\n",
+	"a\tb\n\"c\td\"\n",
+	"x,y\n1\n2,3,4\n",
+	"  ,\t,\n",
+	`\.` + "\n",
+	"\ufeffa,b\n",
+}
+
+// unterminatedInputs end within a quoted field. encoding/csv reports the error at the end of the last
+// non-empty line, and we don't attempt to match that
+var unterminatedInputs = []string{
+	`a,"b` + "\n",
+	`a,"b` + "\r",
+	`a,"b` + "\r\n",
+	`a,"b` + "\nc\n",
+	`"a""b` + "\n\n",
+}
+
+var readerConfigs = []struct {
+	name   string
+	config func(r *stdlib.Reader)
+}{
+	{name: "default", config: func(r *stdlib.Reader) {}},
+	{name: "lazy", config: func(r *stdlib.Reader) { r.LazyQuotes = true }},
+	{name: "trim", config: func(r *stdlib.Reader) { r.TrimLeadingSpace = true }},
+	{name: "lazy trim", config: func(r *stdlib.Reader) { r.LazyQuotes = true; r.TrimLeadingSpace = true }},
+	{name: "comment", config: func(r *stdlib.Reader) { r.Comment = '#' }},
+	{name: "semicolon", config: func(r *stdlib.Reader) { r.Comma = ';' }},
+	{name: "tab", config: func(r *stdlib.Reader) { r.Comma = '\t' }},
+	{name: "variable fields", config: func(r *stdlib.Reader) { r.FieldsPerRecord = -1 }},
+	{name: "fixed fields", config: func(r *stdlib.Reader) { r.FieldsPerRecord = 2 }},
+}
+
+// newReaders creates an encoding/csv Reader and one of ours with the same configuration
+func newReaders(in string, config func(r *stdlib.Reader)) (*stdlib.Reader, *stdcsv.Reader) {
+	exp := stdlib.NewReader(strings.NewReader(in))
+	config(exp)
+
+	// Reading a byte at a time checks we get the positions right across buffer refills
+	r := stdcsv.NewReader(iotest.OneByteReader(strings.NewReader(in)))
+	r.Comma = exp.Comma
+	r.Comment = exp.Comment
+	r.FieldsPerRecord = exp.FieldsPerRecord
+	r.LazyQuotes = exp.LazyQuotes
+	r.TrimLeadingSpace = exp.TrimLeadingSpace
+	return exp, r
+}
+
+func TestReadMatchesStdlib(t *testing.T) {
+	for _, config := range readerConfigs {
+		for _, in := range readInputs {
+			t.Run(fmt.Sprintf("%s/%q", config.name, in), func(t *testing.T) {
+				exp, r := newReaders(in, config.config)
+
+				for {
+					expRecord, expErr := exp.Read()
+					record, err := r.Read()
+					if !assert.Equal(t, expErr, err) {
+						return
+					}
+					if expErr != nil {
+						var pe *stdlib.ParseError
+						if !errors.As(expErr, &pe) {
+							return
+						}
+						if pe.Err == stdlib.ErrFieldCount {
+							// The record is returned along with the error
+							assert.Equal(t, expRecord, record)
+						}
+						// Reading carries on from the next line
+						continue
+					}
+					if !assert.Equal(t, expRecord, record) {
+						return
+					}
+					assert.Equal(t, exp.InputOffset(), r.InputOffset())
+					for i := range record {
+						expLine, expCol := exp.FieldPos(i)
+						line, col := r.FieldPos(i)
+						assert.Equal(t, [2]int{expLine, expCol}, [2]int{line, col}, "field %d", i)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestReadAllMatchesStdlib(t *testing.T) {
+	for _, config := range readerConfigs {
+		for _, in := range readInputs {
+			t.Run(fmt.Sprintf("%s/%q", config.name, in), func(t *testing.T) {
+				exp, r := newReaders(in, config.config)
+				expRecords, expErr := exp.ReadAll()
+				records, err := r.ReadAll()
+				assert.Equal(t, expErr, err)
+				assert.Equal(t, expRecords, records)
+			})
+		}
+	}
+}
+
+func TestReadFieldsPerRecordChanged(t *testing.T) {
+	// encoding/csv reads FieldsPerRecord on every call, so code can change it after reading the header
+	for _, change := range [][2]int{{0, -1}, {-1, 2}, {0, 3}, {2, 0}} {
+		for _, in := range readInputs {
+			t.Run(fmt.Sprintf("%d/%d/%q", change[0], change[1], in), func(t *testing.T) {
+				exp, r := newReaders(in, func(r *stdlib.Reader) { r.FieldsPerRecord = change[0] })
+				for i := 0; ; i++ {
+					if i == 1 {
+						exp.FieldsPerRecord = change[1]
+						r.FieldsPerRecord = change[1]
+					}
+					expRecord, expErr := exp.Read()
+					record, err := r.Read()
+					if !assert.Equal(t, expErr, err) || expErr == io.EOF {
+						return
+					}
+					var pe *stdlib.ParseError
+					if expErr != nil && (!errors.As(expErr, &pe) || pe.Err != stdlib.ErrFieldCount) {
+						// We don't return partial records, and they don't set FieldsPerRecord
+						return
+					}
+					assert.Equal(t, expRecord, record)
+					assert.Equal(t, exp.FieldsPerRecord, r.FieldsPerRecord)
+				}
+			})
+		}
+	}
+}
+
+func TestReadUnterminated(t *testing.T) {
+	for _, config := range readerConfigs {
+		for _, in := range unterminatedInputs {
+			t.Run(fmt.Sprintf("%s/%q", config.name, in), func(t *testing.T) {
+				exp, r := newReaders(in, config.config)
+				expRecords, expErr := exp.ReadAll()
+				records, err := r.ReadAll()
+				assert.Equal(t, expRecords, records)
+
+				var expPE, pe *stdlib.ParseError
+				if expErr == nil || !errors.As(expErr, &expPE) {
+					assert.Equal(t, expErr, err)
+					return
+				}
+				if assert.True(t, errors.As(err, &pe), "error is %v", err) {
+					assert.Equal(t, expPE.Err, pe.Err)
+					assert.Equal(t, expPE.StartLine, pe.StartLine)
+				}
+			})
+		}
+	}
+}
+
+func TestReadAfterError(t *testing.T) {
+	r := stdcsv.NewReader(strings.NewReader(`a,"b"x,c` + "\nd,e\n"))
+	_, err := r.Read()
+	assert.Equal(t, &stdcsv.ParseError{StartLine: 1, Line: 1, Column: 5, Err: stdcsv.ErrQuote}, err)
+	record, err := r.Read()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"d", "e"}, record)
+	_, err = r.Read()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestReuseRecord(t *testing.T) {
+	r := stdcsv.NewReader(strings.NewReader("a,b\nc,d\n"))
+	r.ReuseRecord = true
+
+	first, err := r.Read()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, first)
+	second, err := r.Read()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"c", "d"}, second)
+	assert.Equal(t, &first[0], &second[0])
+
+	_, err = r.Read()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestReadInvalidDelim(t *testing.T) {
+	tests := []struct {
+		name   string
+		config func(r *stdcsv.Reader)
+		err    string
+	}{
+		{
+			name:   "quote",
+			config: func(r *stdcsv.Reader) { r.Comma = '"' },
+			err:    "csv: invalid field or comment delimiter",
+		},
+		{
+			name:   "comment is comma",
+			config: func(r *stdcsv.Reader) { r.Comment = ',' },
+			err:    "csv: invalid field or comment delimiter",
+		},
+		{
+			name:   "non-ASCII",
+			config: func(r *stdcsv.Reader) { r.Comma = '§' },
+			err:    "csv: non-ASCII field or comment delimiter not supported",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := stdcsv.NewReader(strings.NewReader("a,b\n"))
+			test.config(r)
+			_, err := r.Read()
+			assert.EqualError(t, err, test.err)
+		})
+	}
+}
+
+func TestWriteMatchesStdlib(t *testing.T) {
+	records := [][]string{
+		{"a", "b", "c"},
+		{""},
+		{"", ""},
+		{"a,b", "c;d", "e\tf"},
+		{`a"b`, `"`, `""`},
+		{" a", "a ", "\ta", " a"},
+		{"a\nb", "c\r\nd", "e\rf\ng"},
+		{"h\ri", "\r"},
+		{`\.`, `\`, `.`},
+		{"ünïcödé", "日本語"},
+	}
+
+	for _, comma := range []rune{',', ';', '\t'} {
+		for _, useCRLF := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%q/%t", comma, useCRLF), func(t *testing.T) {
+				var expBuf, buf bytes.Buffer
+				exp := stdlib.NewWriter(&expBuf)
+				exp.Comma = comma
+				exp.UseCRLF = useCRLF
+				w := stdcsv.NewWriter(&buf)
+				w.Comma = comma
+				w.UseCRLF = useCRLF
+
+				for _, record := range records {
+					assert.NoError(t, exp.Write(record))
+					assert.NoError(t, w.Write(record))
+				}
+				exp.Flush()
+				w.Flush()
+				assert.NoError(t, exp.Error())
+				assert.NoError(t, w.Error())
+				assert.Equal(t, expBuf.String(), buf.String())
+
+				expBuf.Reset()
+				buf.Reset()
+				assert.NoError(t, exp.WriteAll(records))
+				assert.NoError(t, w.WriteAll(records))
+				assert.Equal(t, expBuf.String(), buf.String())
+			})
+		}
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestWriteError(t *testing.T) {
+	w := stdcsv.NewWriter(failingWriter{})
+	assert.NoError(t, w.Write([]string{"a"}))
+	w.Flush()
+	assert.EqualError(t, w.Error(), "write failed")
+	assert.EqualError(t, w.WriteAll([][]string{{"b"}}), "write failed")
+
+	w = stdcsv.NewWriter(io.Discard)
+	w.Comma = '"'
+	assert.EqualError(t, w.Write([]string{"a"}), "csv: invalid field or comment delimiter")
+}
+
+func FuzzReadMatchesStdlib(f *testing.F) {
+	for _, in := range readInputs {
+		f.Add(in, false)
+		f.Add(in, true)
+	}
+	f.Fuzz(func(t *testing.T, in string, lazy bool) {
+		exp := stdlib.NewReader(strings.NewReader(in))
+		exp.LazyQuotes = lazy
+		exp.FieldsPerRecord = -1
+		r := stdcsv.NewReader(strings.NewReader(in))
+		r.LazyQuotes = lazy
+		r.FieldsPerRecord = -1
+
+		for {
+			expRecord, expErr := exp.Read()
+			record, err := r.Read()
+			if expErr != nil {
+				var expPE, pe *stdlib.ParseError
+				if errors.As(expErr, &expPE) {
+					if !errors.As(err, &pe) || pe.Err != expPE.Err || pe.StartLine != expPE.StartLine {
+						t.Fatalf("expected error %v, got %v", expErr, err)
+					}
+				} else if err != expErr {
+					t.Fatalf("expected error %v, got %v", expErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			assert.Equal(t, expRecord, record)
+			assert.Equal(t, exp.InputOffset(), r.InputOffset())
+		}
+	})
+}
@@ -0,0 +1,97 @@
+package stdcsv
+
+import (
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/philpearl/csv"
+)
+
+// A Writer writes records using CSV encoding. See encoding/csv for details. Writes are buffered, so Flush
+// must be called to ensure that the record has been written to the underlying io.Writer. The fields must be
+// set before the first call to Write. Comma must be an ASCII character.
+type Writer struct {
+	// Comma is the field delimiter. It is set to ',' by NewWriter.
+	Comma rune
+	// UseCRLF makes the Writer use \r\n as the line terminator, and write \n within fields as \r\n.
+	UseCRLF bool
+
+	out io.Writer
+	w   *csv.Writer
+}
+
+// NewWriter returns a new Writer that writes to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{
+		Comma: ',',
+		out:   w,
+	}
+}
+
+// Write writes a single CSV record to w along with any necessary quoting. A record is a slice of strings
+// with each string being one field. Writes are buffered, so Flush must eventually be called to ensure that
+// the record is written to the underlying io.Writer.
+func (w *Writer) Write(record []string) error {
+	if w.w == nil {
+		if err := w.init(); err != nil {
+			return err
+		}
+	}
+
+	for _, field := range record {
+		if w.UseCRLF && strings.ContainsAny(field, "\r\n") {
+			// encoding/csv quotes the field as it is, then drops \r and writes \n as \r\n. The field may
+			// no longer need quotes once the \r is gone
+			w.w.QuotedString(strings.ReplaceAll(strings.ReplaceAll(field, "\r", ""), "\n", "\r\n"))
+			continue
+		}
+		w.w.String(field)
+	}
+	return w.w.LineComplete()
+}
+
+// Flush writes any buffered data to the underlying io.Writer. To check if an error occurred during Flush,
+// call Error.
+func (w *Writer) Flush() {
+	if w.w != nil {
+		w.w.Flush()
+	}
+}
+
+// Error reports any error that has occurred during a previous Write or Flush.
+func (w *Writer) Error() error {
+	if w.w == nil {
+		return nil
+	}
+	return w.w.Error()
+}
+
+// WriteAll writes multiple CSV records to w using Write and then calls Flush, returning any error from the
+// Flush.
+func (w *Writer) WriteAll(records [][]string) error {
+	for _, record := range records {
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// init creates the underlying Writer using the settings in w
+func (w *Writer) init() error {
+	if !validDelim(w.Comma) {
+		return errInvalidDelim
+	}
+	if w.Comma >= utf8.RuneSelf {
+		return errNonASCII
+	}
+
+	d := csv.Dialect{Comma: byte(w.Comma)}
+	if w.UseCRLF {
+		d.LineTerminator = "\r\n"
+	}
+	w.w = csv.NewWriter(w.out, d, csv.BufferSize(4096))
+	return nil
+}
@@ -0,0 +1,90 @@
+package csv
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"unsafe"
+)
+
+// These special layouts can be passed to Reader.Time and Writer.Time in place of a time.Parse layout. They
+// represent times as integer numbers of seconds, milliseconds or nanoseconds since the Unix epoch.
+const (
+	UnixSeconds = "unix"
+	UnixMillis  = "unixmilli"
+	UnixNanos   = "unixnano"
+)
+
+// Time reads the i-th cell of the current row as a time, parsed according to layout. layout may be one of
+// the layouts accepted by time.Parse, or UnixSeconds, UnixMillis or UnixNanos. Times read with the Unix
+// layouts are in UTC. Only valid after a call to Read or Scan.
+func (r *Reader) Time(i int, layout string) (time.Time, error) {
+	if err := r.checkCell(i); err != nil {
+		return time.Time{}, err
+	}
+	b := r.cell(i)
+	s := *(*string)(unsafe.Pointer(&b))
+
+	var t time.Time
+	var err error
+	switch layout {
+	case UnixSeconds, UnixMillis, UnixNanos:
+		var v int64
+		v, err = strconv.ParseInt(s, 10, 64)
+		switch layout {
+		case UnixSeconds:
+			t = time.Unix(v, 0).UTC()
+		case UnixMillis:
+			t = time.UnixMilli(v).UTC()
+		default:
+			t = time.Unix(0, v).UTC()
+		}
+	default:
+		if strings.Contains(layout, "MST") {
+			// time.Parse keeps the zone abbreviation from the value, so the value must be a real string
+			s = string(b)
+		}
+		t, err = time.Parse(layout, s)
+	}
+	if err != nil {
+		return time.Time{}, r.cellError(i, err)
+	}
+	return t, nil
+}
+
+// Duration reads the i-th cell of the current row as a duration in the format accepted by
+// time.ParseDuration. Only valid after a call to Read or Scan.
+func (r *Reader) Duration(i int) (time.Duration, error) {
+	if err := r.checkCell(i); err != nil {
+		return 0, err
+	}
+	b := r.cell(i)
+	d, err := time.ParseDuration(*(*string)(unsafe.Pointer(&b)))
+	if err != nil {
+		return 0, r.cellError(i, err)
+	}
+	return d, nil
+}
+
+// Time writes a time cell value to the CSV, formatted according to layout. layout may be one of the layouts
+// accepted by time.Time.Format, or UnixSeconds, UnixMillis or UnixNanos.
+func (w *Writer) Time(t time.Time, layout string) {
+	switch layout {
+	case UnixSeconds:
+		w.Int64(t.Unix())
+	case UnixMillis:
+		w.Int64(t.UnixMilli())
+	case UnixNanos:
+		w.Int64(t.UnixNano())
+	default:
+		// The layout could include characters that need quoting, so format into scratch space first
+		w.scratch = t.AppendFormat(w.scratch[:0], layout)
+		w.Bytes(w.scratch)
+	}
+}
+
+// Duration writes a duration cell value to the CSV, in the format used by time.Duration.String
+func (w *Writer) Duration(d time.Duration) {
+	w.comma()
+	w.b = append(w.b, d.String()...)
+}
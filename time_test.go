@@ -0,0 +1,143 @@
+package csv_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/philpearl/csv"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadTime(t *testing.T) {
+	r := csv.NewReader(strings.NewReader(`2024-03-04T05:06:07.123Z,2024-03-04T05:06:07+01:00,"Mon, 04 Mar 2024 05:06:07 CET",1709528767,1709528767123,1709528767123456789,nope`))
+	assert.NoError(t, r.Scan())
+
+	exp := time.Date(2024, 3, 4, 5, 6, 7, 123000000, time.UTC)
+	tm, err := r.Time(0, time.RFC3339)
+	assert.NoError(t, err)
+	assert.True(t, exp.Equal(tm))
+
+	tm, err = r.Time(1, time.RFC3339)
+	assert.NoError(t, err)
+	assert.True(t, time.Date(2024, 3, 4, 4, 6, 7, 0, time.UTC).Equal(tm))
+
+	tm, err = r.Time(2, time.RFC1123)
+	assert.NoError(t, err)
+	name, _ := tm.Zone()
+	assert.Equal(t, "CET", name)
+	// The zone name must not refer to the Reader's buffer
+	r.SetInput(strings.NewReader("xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx"))
+	assert.NoError(t, r.Scan())
+	name, _ = tm.Zone()
+	assert.Equal(t, "CET", name)
+
+	r.SetInput(strings.NewReader(`1709528767,1709528767123,1709528767123456789,nope`))
+	assert.NoError(t, r.Scan())
+
+	tm, err = r.Time(0, csv.UnixSeconds)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 3, 4, 5, 6, 7, 0, time.UTC), tm)
+
+	tm, err = r.Time(1, csv.UnixMillis)
+	assert.NoError(t, err)
+	assert.Equal(t, exp, tm)
+
+	tm, err = r.Time(2, csv.UnixNanos)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 3, 4, 5, 6, 7, 123456789, time.UTC), tm)
+
+	_, err = r.Time(3, csv.UnixSeconds)
+	assert.True(t, errors.Is(err, strconv.ErrSyntax))
+	_, err = r.Time(3, time.RFC3339)
+	assert.EqualError(t, err, `record 1, cell 3 (line 1, offset 45): parsing time "nope" as "2006-01-02T15:04:05Z07:00": cannot parse "nope" as "2006"`)
+}
+
+func TestReadDuration(t *testing.T) {
+	r := csv.NewReader(strings.NewReader("1h2m3.5s,-7ms,0,12"))
+	assert.NoError(t, r.Scan())
+
+	d, err := r.Duration(0)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Hour+2*time.Minute+3500*time.Millisecond, d)
+
+	d, err = r.Duration(1)
+	assert.NoError(t, err)
+	assert.Equal(t, -7*time.Millisecond, d)
+
+	d, err = r.Duration(2)
+	assert.NoError(t, err)
+	assert.Zero(t, d)
+
+	_, err = r.Duration(3)
+	assert.EqualError(t, err, `record 1, cell 3 (line 1, offset 16): time: missing unit in duration "12"`)
+}
+
+func TestWriteTime(t *testing.T) {
+	tm := time.Date(2024, 3, 4, 5, 6, 7, 123456789, time.UTC)
+
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	w.Time(tm, time.RFC3339Nano)
+	w.Time(tm, time.RFC1123)
+	w.Time(tm, csv.UnixSeconds)
+	w.Time(tm, csv.UnixMillis)
+	w.Time(tm, csv.UnixNanos)
+	w.Duration(90 * time.Minute)
+	w.Duration(1500 * time.Microsecond)
+	assert.NoError(t, w.LineComplete())
+
+	assert.Equal(t, "2024-03-04T05:06:07.123456789Z,\"Mon, 04 Mar 2024 05:06:07 UTC\",1709528767,1709528767123,1709528767123456789,1h30m0s,1.5ms\n", b.String())
+
+	// And read it back
+	r := csv.NewReader(&b)
+	assert.NoError(t, r.Scan())
+	for i, layout := range []string{time.RFC3339Nano, time.RFC1123, csv.UnixSeconds, csv.UnixMillis, csv.UnixNanos} {
+		actual, err := r.Time(i, layout)
+		assert.NoError(t, err)
+		assert.True(t, tm.Truncate(time.Second).Equal(actual.Truncate(time.Second)), "%s: %s", layout, actual)
+	}
+	d, err := r.Duration(5)
+	assert.NoError(t, err)
+	assert.Equal(t, 90*time.Minute, d)
+	d, err = r.Duration(6)
+	assert.NoError(t, err)
+	assert.Equal(t, 1500*time.Microsecond, d)
+}
+
+func TestTimeAllocs(t *testing.T) {
+	tm := time.Date(2024, 3, 4, 5, 6, 7, 123456789, time.UTC)
+	w := csv.NewWriter(io.Discard)
+	r := csv.NewReader(&repeatReader{content: []byte("2024-03-04T05:06:07.123456789Z,1709528767,1h30m0s\n")})
+
+	// Warm up buffers
+	w.Time(tm, time.RFC3339Nano)
+	assert.NoError(t, w.LineComplete())
+
+	allocs := testing.AllocsPerRun(100, func() {
+		w.Time(tm, time.RFC3339Nano)
+		w.Time(tm, csv.UnixMillis)
+		w.Duration(90 * time.Minute)
+		if err := w.LineComplete(); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := r.Scan(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := r.Time(0, time.RFC3339); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := r.Time(1, csv.UnixSeconds); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := r.Duration(2); err != nil {
+			t.Fatal(err)
+		}
+	})
+	assert.Zero(t, allocs)
+}
@@ -1,10 +1,9 @@
 package csv
 
 import (
-	"bytes"
+	"context"
 	"io"
 	"strconv"
-	"strings"
 	"unicode"
 	"unicode/utf8"
 )
@@ -14,13 +13,41 @@ type Writer struct {
 	w     io.Writer
 	b     []byte
 	count int
+
+	// b[:done] contains complete lines that have not been written yet. We write them once there are at least
+	// bufSize bytes. err is the first error we saw writing.
+	done    int
+	bufSize int
+	err     error
+
+	delim          byte
+	quote          byte
+	lineTerminator string
+
+	// scratch space for formatting values that may need quoting
+	scratch []byte
+
+	// quoteEmpty is set if empty strings should be written as "". See QuoteEmpty
+	quoteEmpty bool
+
+	// closer is closed by Close. See NewGzipWriter
+	closer io.Closer
 }
 
-// NewWriter creates a new CSV writer
-func NewWriter(w io.Writer) *Writer {
-	return &Writer{
-		w: w,
+// NewWriter creates a new CSV writer. By default it writes comma separated data quoted with '"' and
+// terminates lines with "\n". Pass a Dialect to write other formats. NewWriter panics if the Dialect is
+// invalid.
+func NewWriter(w io.Writer, opts ...WriterOption) *Writer {
+	wr := &Writer{
+		w:              w,
+		delim:          DefaultDialect.Comma,
+		quote:          DefaultDialect.Quote,
+		lineTerminator: DefaultDialect.LineTerminator,
+	}
+	for _, opt := range opts {
+		opt.applyWriter(wr)
 	}
+	return wr
 }
 
 // String writes a string cell value to the CSV. It escapes the string value if necessary
@@ -30,20 +57,30 @@ func (w *Writer) String(s string) {
 		w.b = append(w.b, s...)
 		return
 	}
-	w.b = append(w.b, '"')
+	w.appendQuoted(s)
+}
+
+// QuotedString writes a string cell value to the CSV, enclosing it in quotes whether or not it needs them.
+func (w *Writer) QuotedString(s string) {
+	w.comma()
+	w.appendQuoted(s)
+}
+
+func (w *Writer) appendQuoted(s string) {
+	w.b = append(w.b, w.quote)
 	// If we range through a string by value we'll be given runes. But we don't need runes as we only need to
-	// look for ", and no byte of a utf8 char will match unless it is a "
+	// look for the quote, and no byte of a utf8 char will match an ASCII quote
 	for i := 0; i < len(s); i++ {
 		c := s[i]
 		switch c {
-		case '"':
-			w.b = append(w.b, '"', '"')
+		case w.quote:
+			w.b = append(w.b, c, c)
 		default:
 			// Even other special characters are just copied
 			w.b = append(w.b, c)
 		}
 	}
-	w.b = append(w.b, '"')
+	w.b = append(w.b, w.quote)
 }
 
 // Bytes writes a []byte as a cell value to the CSV. The []byte is assumed to be a string. It is used where
@@ -54,20 +91,20 @@ func (w *Writer) Bytes(s []byte) {
 		w.b = append(w.b, s...)
 		return
 	}
-	w.b = append(w.b, '"')
+	w.b = append(w.b, w.quote)
 	// If we range through a string by value we'll be given runes. But we don't need runes as we only need to
-	// look for ", and no byte of a utf8 char will match unless it is a "
+	// look for the quote, and no byte of a utf8 char will match an ASCII quote
 	for i := range s {
 		c := s[i]
 		switch c {
-		case '"':
-			w.b = append(w.b, '"', '"')
+		case w.quote:
+			w.b = append(w.b, c, c)
 		default:
 			// Even other special characters are just copied
 			w.b = append(w.b, c)
 		}
 	}
-	w.b = append(w.b, '"')
+	w.b = append(w.b, w.quote)
 }
 
 // Bool writes a bool cell value to the CSV
@@ -82,35 +119,144 @@ func (w *Writer) Float64(f float64) {
 	w.b = strconv.AppendFloat(w.b, f, 'g', -1, 64)
 }
 
+// Float32 writes a float32 cell value to the CSV. It uses the fewest digits that will read back as the same
+// float32
+func (w *Writer) Float32(f float32) {
+	w.comma()
+	w.b = strconv.AppendFloat(w.b, float64(f), 'g', -1, 32)
+}
+
+// FloatFormat writes a float cell value to the CSV, formatted according to format and prec. bitSize is 32
+// for float32 values and 64 for float64. See strconv.FormatFloat for the meaning of the parameters
+func (w *Writer) FloatFormat(f float64, format byte, prec, bitSize int) {
+	w.comma()
+	w.b = strconv.AppendFloat(w.b, f, format, prec, bitSize)
+}
+
 // Int64 writes an int64 cell value to the CSV
 func (w *Writer) Int64(i int64) {
 	w.comma()
 	w.b = strconv.AppendInt(w.b, i, 10)
 }
 
+// Int32 writes an int32 cell value to the CSV
+func (w *Writer) Int32(i int32) {
+	w.comma()
+	w.b = strconv.AppendInt(w.b, int64(i), 10)
+}
+
+// Uint64 writes a uint64 cell value to the CSV
+func (w *Writer) Uint64(i uint64) {
+	w.comma()
+	w.b = strconv.AppendUint(w.b, i, 10)
+}
+
 // Skip skips a field - so just writes a comma
 func (w *Writer) Skip() {
 	w.comma()
 }
 
-// LineComplete finishes the CSV file line and writes it to the output
+// Null writes an empty, unquoted cell. Readers such as Postgres treat this as NULL. Unless QuoteEmpty is set
+// this is the same as writing an empty string.
+func (w *Writer) Null() {
+	w.comma()
+}
+
+// QuoteEmpty makes the Writer quote empty strings, so that they can be distinguished from NULL values
+// written with Null. By default empty strings are not quoted.
+func QuoteEmpty() WriterOption {
+	return writerOptionFunc(func(w *Writer) {
+		w.quoteEmpty = true
+	})
+}
+
+// BufferSize makes the Writer keep complete lines in memory until it has at least size bytes to write. Use
+// Flush to write any remaining lines once you are done. Without this option each line is written as soon as
+// it is complete.
+func BufferSize(size int) WriterOption {
+	return writerOptionFunc(func(w *Writer) {
+		w.bufSize = size
+	})
+}
+
+// LineComplete finishes the CSV file line and writes it to the output, unless BufferSize is set and the
+// buffer is not yet full. Once a write has failed every subsequent call returns the same error.
 func (w *Writer) LineComplete() error {
-	w.b = append(w.b, '\n')
-	_, err := w.w.Write(w.b)
-	w.b = w.b[:0]
+	w.endLine()
+	if w.done < w.bufSize {
+		return w.err
+	}
+	return w.Flush()
+}
+
+// LineCompleteContext is LineComplete, but returns ctx.Err() rather than writing if ctx is done. The line is
+// still completed, and is written by the next call to LineComplete or Flush. A write that is already in
+// progress is not interrupted.
+func (w *Writer) LineCompleteContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		w.endLine()
+		return err
+	}
+	return w.LineComplete()
+}
+
+// RawLine writes line to the output exactly as it is, as a complete line. It is intended for passing records
+// read using RetainRaw and RawRecord through untouched. The line terminator is added if line doesn't end with
+// "\n". RawLine must only be called between lines. As with LineComplete the line is written unless BufferSize
+// is set and the buffer is not yet full.
+func (w *Writer) RawLine(line []byte) error {
+	w.b = append(w.b, line...)
+	if n := len(line); n > 0 && line[n-1] == '\n' {
+		w.done = len(w.b)
+	} else {
+		w.endLine()
+	}
+	if w.done < w.bufSize {
+		return w.err
+	}
+	return w.Flush()
+}
+
+// endLine terminates the current line, which makes it ready to write
+func (w *Writer) endLine() {
+	w.b = append(w.b, w.lineTerminator...)
+	w.count = 0
+	w.done = len(w.b)
+}
+
+// Flush writes any complete lines that are held in the buffer. A line that has been started but not
+// completed with LineComplete is kept.
+func (w *Writer) Flush() error {
+	if w.err == nil && w.done > 0 {
+		_, w.err = w.w.Write(w.b[:w.done])
+	}
+	// Move any partial line to the start of the buffer. If the write failed we discard the lines that
+	// didn't get written so we don't keep growing the buffer.
+	w.b = w.b[:copy(w.b, w.b[w.done:])]
+	w.done = 0
+	return w.err
+}
+
+// Error returns the first error that occurred writing to the output.
+func (w *Writer) Error() error {
+	return w.err
+}
+
+// truncate discards the current line from offset n of the buffer. n must be at least done
+func (w *Writer) truncate(n int) {
+	w.b = w.b[:n]
 	w.count = 0
-	return err
 }
 
 func (w *Writer) comma() {
 	if w.count != 0 {
-		w.b = append(w.b, ',')
+		w.b = append(w.b, w.delim)
 	}
 	w.count++
 }
 
 // fieldNeedsQuotes reports whether our field must be enclosed in quotes.
-// Fields with a Comma, fields with a quote or newline, and
+// Fields with the delimiter, fields with a quote or newline, and
 // fields which start with a space must be enclosed in quotes.
 // We used to quote empty strings, but we do not anymore (as of Go 1.4).
 // The two representations should be equivalent, but Postgres distinguishes
@@ -119,28 +265,35 @@ func (w *Writer) comma() {
 // no option to force the non-quoted behavior for quoted CSV, making
 // CSV with quoted empty strings strictly less useful.
 // Not quoting the empty string also makes this package match the behavior
-// of Microsoft Excel and Google Drive.
+// of Microsoft Excel and Google Drive. Use QuoteEmpty to quote empty strings.
 // For Postgres, quote the data terminating string `\.`.
 //
 // Lifted from the Go source
-func (*Writer) fieldNeedsQuotes(field string) bool {
+func (w *Writer) fieldNeedsQuotes(field string) bool {
 	if field == "" {
-		return false
+		return w.quoteEmpty
 	}
-	if field == `\.` || strings.ContainsAny(field, ",\"\r\n") {
+	if field == `\.` {
 		return true
 	}
+	for i := 0; i < len(field); i++ {
+		if w.isSpecial(field[i]) {
+			return true
+		}
+	}
 
 	r1, _ := utf8.DecodeRuneInString(field)
 	return unicode.IsSpace(r1)
 }
 
-func (*Writer) byteFieldNeedsQuotes(field []byte) bool {
+func (w *Writer) byteFieldNeedsQuotes(field []byte) bool {
 	if len(field) == 0 {
-		return false
+		return w.quoteEmpty
 	}
-	if bytes.ContainsAny(field, ",\"\r\n") {
-		return true
+	for _, c := range field {
+		if w.isSpecial(c) {
+			return true
+		}
 	}
 	if len(field) == 2 && field[0] == '\\' && field[1] == '.' {
 		return true
@@ -149,3 +302,8 @@ func (*Writer) byteFieldNeedsQuotes(field []byte) bool {
 	r1, _ := utf8.DecodeRune(field)
 	return unicode.IsSpace(r1)
 }
+
+// isSpecial reports whether c is a character that forces a field to be quoted.
+func (w *Writer) isSpecial(c byte) bool {
+	return c == w.delim || c == w.quote || c == '\r' || c == '\n'
+}
@@ -2,7 +2,9 @@ package csv_test
 
 import (
 	"bytes"
+	"context"
 	stdcsv "encoding/csv"
+	"errors"
 	"os"
 	"strconv"
 	"testing"
@@ -191,6 +193,123 @@ func TestWriter(t *testing.T) {
 	}
 }
 
+func TestWriterDialect(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect csv.Dialect
+		exp     string
+	}{
+		{
+			name:    "tab",
+			dialect: csv.Dialect{Comma: '\t'},
+			exp:     "a,b\t\"c\td\"\t\"e\"\"f\"\n",
+		},
+		{
+			name:    "semicolon single quote crlf",
+			dialect: csv.Dialect{Comma: ';', Quote: '\'', LineTerminator: "\r\n"},
+			exp:     "a,b;c\td;e\"f\r\n",
+		},
+		{
+			name:    "single quote",
+			dialect: csv.Dialect{Quote: '\''},
+			exp:     "'a,b',c\td,e\"f\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var b bytes.Buffer
+			w := csv.NewWriter(&b, test.dialect)
+			w.String("a,b")
+			w.Bytes([]byte("c\td"))
+			w.String("e\"f")
+			assert.NoError(t, w.LineComplete())
+			assert.Equal(t, test.exp, b.String())
+		})
+	}
+}
+
+func TestWriterQuoteInDialect(t *testing.T) {
+	var b bytes.Buffer
+	w := csv.NewWriter(&b, csv.Dialect{Quote: '\''})
+	w.String("it's")
+	w.Bytes([]byte("'"))
+	assert.NoError(t, w.LineComplete())
+	assert.Equal(t, "'it''s',''''\n", b.String())
+}
+
+func TestWriterQuotedString(t *testing.T) {
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	w.QuotedString("a")
+	w.QuotedString(`b"c`)
+	w.QuotedString("")
+	w.String("d")
+	assert.NoError(t, w.LineComplete())
+	assert.Equal(t, "\"a\",\"b\"\"c\",\"\",d\n", b.String())
+}
+
+type countingWriter struct {
+	bytes.Buffer
+	writes int
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	c.writes++
+	return c.Buffer.Write(p)
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestWriterBuffered(t *testing.T) {
+	var out countingWriter
+	w := csv.NewWriter(&out, csv.BufferSize(20))
+
+	for i := range 5 {
+		w.Int64(int64(i))
+		w.String("hello")
+		assert.NoError(t, w.LineComplete())
+	}
+	// Lines are 8 bytes, so we write when we've completed 3
+	assert.Equal(t, 1, out.writes)
+	assert.Equal(t, "0,hello\n1,hello\n2,hello\n", out.String())
+
+	// Flush only writes complete lines
+	w.String("partial")
+	assert.NoError(t, w.Flush())
+	assert.Equal(t, 2, out.writes)
+	assert.Equal(t, "0,hello\n1,hello\n2,hello\n3,hello\n4,hello\n", out.String())
+
+	assert.NoError(t, w.LineComplete())
+	assert.NoError(t, w.Flush())
+	assert.NoError(t, w.Error())
+	assert.Equal(t, 3, out.writes)
+	assert.Equal(t, "0,hello\n1,hello\n2,hello\n3,hello\n4,hello\npartial\n", out.String())
+
+	// Nothing to flush
+	assert.NoError(t, w.Flush())
+	assert.Equal(t, 3, out.writes)
+}
+
+func TestWriterError(t *testing.T) {
+	w := csv.NewWriter(failingWriter{})
+	w.String("a")
+	assert.EqualError(t, w.LineComplete(), "disk full")
+	w.String("b")
+	assert.EqualError(t, w.LineComplete(), "disk full")
+	assert.EqualError(t, w.Error(), "disk full")
+
+	w = csv.NewWriter(failingWriter{}, csv.BufferSize(100))
+	w.String("a")
+	assert.NoError(t, w.LineComplete())
+	assert.EqualError(t, w.Flush(), "disk full")
+	assert.EqualError(t, w.Error(), "disk full")
+}
+
 func TestStandardWriterEmpty(t *testing.T) {
 	var b bytes.Buffer
 	w := stdcsv.NewWriter(&b)
@@ -218,6 +337,38 @@ func BenchmarkWriter(b *testing.B) {
 	}
 }
 
+func BenchmarkWriterFile(b *testing.B) {
+	benchmarkWriterFile(b)
+}
+
+func BenchmarkWriterFileBuffered(b *testing.B) {
+	benchmarkWriterFile(b, csv.BufferSize(64*1024))
+}
+
+// benchmarkWriterFile writes short lines to a file, where the cost of each write call is significant
+func benchmarkWriterFile(b *testing.B, opts ...csv.WriterOption) {
+	f, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer f.Close()
+	w := csv.NewWriter(f, opts...)
+
+	b.ResetTimer()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		w.String("hatah")
+		w.Int64(int64(i))
+		w.Float64(1.382)
+		if err := w.LineComplete(); err != nil {
+			b.Fatalf("failed %s", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		b.Fatal(err)
+	}
+}
+
 func BenchmarkStandardWriter(b *testing.B) {
 	var buf bytes.Buffer
 	w := stdcsv.NewWriter(&buf)
@@ -236,3 +387,38 @@ func BenchmarkStandardWriter(b *testing.B) {
 		}
 	}
 }
+
+func TestLineCompleteContext(t *testing.T) {
+	var out bytes.Buffer
+	w := csv.NewWriter(&out)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w.String("a")
+	assert.NoError(t, w.LineCompleteContext(ctx))
+	assert.Equal(t, "a\n", out.String())
+
+	cancel()
+	w.String("b")
+	assert.Equal(t, context.Canceled, w.LineCompleteContext(ctx))
+	assert.Equal(t, "a\n", out.String())
+
+	// The cancelled line is written with the next one
+	w.String("c")
+	assert.NoError(t, w.LineComplete())
+	assert.Equal(t, "a\nb\nc\n", out.String())
+}
+
+func TestWriterRawLine(t *testing.T) {
+	var out countingWriter
+	w := csv.NewWriter(&out, csv.BufferSize(20))
+	w.String("a b")
+	assert.NoError(t, w.LineComplete())
+	assert.NoError(t, w.RawLine([]byte("\"c\"\"\",d\r\n")))
+	// A line terminator is added if there isn't one
+	assert.NoError(t, w.RawLine([]byte("e,\"f")))
+	assert.Equal(t, 0, out.writes)
+	w.String("g")
+	assert.NoError(t, w.LineComplete())
+	assert.Equal(t, 1, out.writes)
+	assert.Equal(t, "a b\n\"c\"\"\",d\r\ne,\"f\ng\n", out.String())
+}